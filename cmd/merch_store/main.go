@@ -1,61 +1,245 @@
 package main
 
 import (
-	"github.com/ners1us/merch_store/internal/model"
-	"log"
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ners1us/merch_store/internal/config"
+	"github.com/ners1us/merch_store/internal/enum"
 	"github.com/ners1us/merch_store/internal/handler"
+	"github.com/ners1us/merch_store/internal/keys"
+	"github.com/ners1us/merch_store/internal/logging"
+	"github.com/ners1us/merch_store/internal/metrics"
+	"github.com/ners1us/merch_store/internal/migration"
 	"github.com/ners1us/merch_store/internal/repository"
 	"github.com/ners1us/merch_store/internal/service"
+	"github.com/ners1us/merch_store/internal/tracing"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 func main() {
+	seedOnly := flag.Bool("seed-only", false, "seed the merch catalog from the catalog file and exit")
+	seedDryRun := flag.Bool("seed-dry-run", false, "print the catalog changes seeding would make and exit")
+	seedUpdatePrices := flag.Bool("seed-update-prices", false, "overwrite prices of existing merch items with the catalog file")
+	reconcile := flag.Bool("reconcile", false, "report users whose cached balance differs from the ledger and exit")
+	flag.Parse()
+
 	cfg := config.InitConfig()
+	ctx := context.Background()
+
+	logger, err := logging.New(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		fatal("failed to configure logging", err)
+	}
+	slog.SetDefault(logger)
+
+	tracerProvider, shutdownTracing, err := tracing.Setup(ctx, cfg.TracesExporter)
+	if err != nil {
+		fatal("failed to configure tracing", err)
+	}
+
+	db, err := gorm.Open(postgres.Open(cfg.DbUrl), &gorm.Config{Logger: logging.NewGormLogger(cfg.SlowQueryTime)})
+	if err != nil {
+		fatal("failed to connect to database", err)
+	}
+
+	if err := tracing.InstrumentDB(db, tracerProvider); err != nil {
+		fatal("failed to instrument database queries", err)
+	}
 
-	db, err := gorm.Open(postgres.Open(cfg.DbUrl), &gorm.Config{})
+	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatal("failed to connect to database: ", err)
+		fatal("failed to access database connection", err)
+	}
+	appMetrics := metrics.New()
+	if err := appMetrics.RegisterDB(sqlDB); err != nil {
+		fatal("failed to register database metrics", err)
 	}
 
-	if err := db.AutoMigrate(&model.User{}, &model.Merch{}, &model.Purchase{}, &model.CoinTransfer{}); err != nil {
-		log.Fatal("failed to migrate database: ", err)
+	migrator, err := migration.NewMigrator(sqlDB)
+	if err != nil {
+		fatal("failed to load migrations", err)
+	}
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(migrator, flag.Args()[1:]); err != nil {
+			fatal("migration failed", err)
+		}
+		return
+	}
+	// A dry run only reads the catalog, so it neither migrates the schema nor opens ledger balances
+	if !*seedDryRun {
+		applied, err := migrator.Up()
+		if err != nil {
+			fatal("failed to migrate database", err)
+		}
+		for _, m := range applied {
+			slog.Info("applied migration", "version", m.Version, "name", m.Name)
+		}
 	}
 
 	userRepo := repository.NewUserRepository(db)
 	merchRepo := repository.NewMerchRepository(db)
 	purchaseRepo := repository.NewPurchaseRepository(db)
 	transferRepo := repository.NewCoinTransferRepository(db)
+	idempotencyRepo := repository.NewIdempotencyKeyRepository(db)
+	orderRepo := repository.NewOrderRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	revokedTokenRepo := repository.NewRevokedTokenRepository(db)
+	adjustmentRepo := repository.NewCoinAdjustmentRepository(db)
+	ledgerRepo := repository.NewLedgerRepository(db)
 
-	err = merchRepo.InitializeMerch()
+	ledgerService := service.NewLedgerService(userRepo, ledgerRepo)
+	if !*seedDryRun {
+		opened, err := ledgerService.OpenBalances(ctx)
+		if err != nil {
+			fatal("failed to open ledger balances", err)
+		}
+		if opened > 0 {
+			slog.Info("opened ledger balances for existing users", "users", opened)
+		}
+	}
+	// Balances are reconciled after the legacy users got their opening entries, otherwise all of them would differ
+	if *reconcile {
+		mismatches, err := ledgerService.Reconcile(ctx)
+		if err != nil {
+			fatal("failed to reconcile balances", err)
+		}
+		for _, mismatch := range mismatches {
+			slog.Warn("balance differs from the ledger", "user_id", mismatch.UserID, "username", mismatch.Username,
+				"cached_coins", mismatch.CachedCoins, "ledger_coins", mismatch.LedgerCoins)
+		}
+		if len(mismatches) > 0 {
+			fatal("failed to reconcile balances", fmt.Errorf("%d balances differ from the ledger", len(mismatches)))
+		}
+		slog.Info("all balances match the ledger")
+		return
+	}
+
+	catalog, err := config.LoadCatalog(cfg.CatalogFile)
+	if err != nil {
+		fatal("failed to load the merch catalog", err)
+	}
+	catalogService := service.NewCatalogService(merchRepo)
+	changes, err := catalogService.SeedCatalog(ctx, catalog, *seedUpdatePrices, *seedDryRun)
 	if err != nil {
-		log.Fatal("failed to initialize data in the merch table: ", err)
+		fatal("failed to seed the merch catalog", err)
+	}
+	for _, change := range changes {
+		if change.Action != enum.SeedUnchanged {
+			slog.Info("catalog changed", "action", change.Action, "name", change.Name, "old_price", change.OldPrice, "new_price", change.NewPrice)
+		}
+	}
+	if *seedOnly || *seedDryRun {
+		return
 	}
 
-	authService := service.NewAuthService(userRepo, []byte(cfg.JWTSecret))
-	userService := service.NewUserService(userRepo, purchaseRepo, transferRepo)
-	merchService := service.NewMerchService(userRepo, merchRepo, purchaseRepo)
-	transferService := service.NewTransferService(userRepo, transferRepo)
+	keySet, err := keys.LoadKeySet(cfg)
+	if err != nil {
+		fatal("failed to load JWT keys", err)
+	}
+
+	tokenService := service.NewTokenService(userRepo, refreshTokenRepo, revokedTokenRepo, keySet, cfg.AccessTTL, cfg.RefreshTTL)
+	authService := service.NewAuthService(userRepo, ledgerRepo, tokenService, service.RegistrationPolicy{
+		AutoRegister: cfg.AutoRegister,
+		InviteCodes:  cfg.InviteCodes,
+		AllowedUsers: cfg.AllowedUsers,
+	})
+	authService = service.NewAuthServiceWithMetrics(service.NewAuthServiceWithTracing(authService, tracerProvider), appMetrics)
+	if cfg.AdminUsername != "" {
+		if err := authService.EnsureAdmin(ctx, cfg.AdminUsername, cfg.AdminPassword); err != nil {
+			fatal("failed to bootstrap the admin user", err)
+		}
+	}
+
+	userService := service.NewUserService(userRepo, purchaseRepo, transferRepo, adjustmentRepo)
+	userService = service.NewUserServiceWithTracing(userService, tracerProvider)
+	merchService := service.NewMerchService(userRepo, merchRepo, purchaseRepo, ledgerRepo)
+	merchService = service.NewMerchServiceWithMetrics(service.NewMerchServiceWithTracing(merchService, tracerProvider), appMetrics)
+	transferService := service.NewTransferService(userRepo, transferRepo, ledgerRepo)
+	transferService = service.NewTransferServiceWithMetrics(service.NewTransferServiceWithTracing(transferService, tracerProvider), appMetrics)
+	adjustmentService := service.NewAdjustmentService(userRepo, adjustmentRepo, ledgerRepo)
+	historyService := service.NewHistoryService(ledgerRepo)
+	refundService := service.NewRefundService(userRepo, merchRepo, purchaseRepo, ledgerRepo, cfg.RefundWindow)
+	orderService := service.NewOrderServiceWithMetrics(service.NewOrderService(userRepo, merchRepo, orderRepo, ledgerRepo), appMetrics)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo, cfg.IdempotencyTTL, 2*cfg.RequestTimeout)
 
 	authHandler := handler.NewAuthHandler(authService)
 	infoHandler := handler.NewInfoHandler(userService)
+	localeHandler := handler.NewLocaleHandler(userService)
 	buyHandler := handler.NewBuyHandler(merchService)
 	sendCoinHandler := handler.NewSendCoinHandler(transferService)
+	catalogHandler := handler.NewCatalogHandler(catalogService)
+	orderHandler := handler.NewOrderHandler(orderService)
+	adjustmentHandler := handler.NewAdjustmentHandler(adjustmentService)
+	historyHandler := handler.NewHistoryHandler(historyService)
+	refundHandler := handler.NewRefundHandler(refundService)
+	jwksHandler := handler.NewJWKSHandler(keySet)
+
+	authMiddleware := handler.AuthMiddleware(tokenService)
+	adminMiddleware := handler.RequireRole(enum.RoleAdmin)
 
-	r := gin.Default()
+	r := gin.New()
+	r.Use(handler.TracingMiddleware(tracerProvider))
+	r.Use(handler.RequestLogger())
+	r.Use(handler.MetricsMiddleware(appMetrics))
+	r.Use(handler.LocaleMiddleware(userService))
+	r.Use(handler.ErrorMiddleware())
+	r.Use(handler.RecoveryMiddleware())
+	r.Use(handler.TimeoutMiddleware(cfg.RequestTimeout))
+	r.GET("/.well-known/jwks.json", jwksHandler.HandleJWKS)
+	r.GET("/metrics", handler.MetricsHandler(appMetrics))
 	api := r.Group("/api")
 	{
 		api.POST("/auth", authHandler.HandleAuth)
-		api.GET("/info", handler.AuthMiddleware([]byte(cfg.JWTSecret)), infoHandler.HandleInfo)
-		api.POST("/sendCoin", handler.AuthMiddleware([]byte(cfg.JWTSecret)), sendCoinHandler.HandleSendCoin)
-		api.GET("/buy/:item", handler.AuthMiddleware([]byte(cfg.JWTSecret)), buyHandler.HandleBuy)
+		api.POST("/register", authHandler.HandleRegister)
+		api.POST("/auth/refresh", authHandler.HandleRefresh)
+		api.POST("/logout", authMiddleware, authHandler.HandleLogout)
+		api.GET("/info", authMiddleware, infoHandler.HandleInfo)
+		api.GET("/history", authMiddleware, historyHandler.HandleHistory)
+		api.PUT("/locale", authMiddleware, localeHandler.HandleSetLocale)
+		api.POST("/sendCoin", authMiddleware, handler.IdempotencyMiddleware(idempotencyService), sendCoinHandler.HandleSendCoin)
+		api.GET("/buy/:item", authMiddleware, handler.IdempotencyMiddleware(idempotencyService), buyHandler.HandleBuy)
+		api.POST("/orders", authMiddleware, handler.IdempotencyMiddleware(idempotencyService), orderHandler.HandlePlaceOrder)
+		api.POST("/purchases/:id/refund", authMiddleware, refundHandler.HandleRefund)
+		api.GET("/admin/users/:username/info", authMiddleware, adminMiddleware, infoHandler.HandleUserInfo)
+		api.POST("/admin/users/:username/credit", authMiddleware, adminMiddleware, adjustmentHandler.HandleCredit)
+		api.POST("/admin/users/:username/debit", authMiddleware, adminMiddleware, adjustmentHandler.HandleDebit)
+		api.GET("/merch", authMiddleware, catalogHandler.HandleListMerch)
+		api.POST("/merch/:name", authMiddleware, adminMiddleware, catalogHandler.HandleAddMerch)
+		api.PUT("/merch/:name", authMiddleware, adminMiddleware, catalogHandler.HandleUpdateMerch)
+		api.DELETE("/merch/:name", authMiddleware, adminMiddleware, catalogHandler.HandleRetireMerch)
 	}
 
-	err = r.Run(":" + cfg.Port)
-	if err != nil {
-		log.Fatal("failed running merch store service: ", err)
+	// Traces are flushed and the pool is closed even when the server fails or can't drain in time,
+	// the process exits with a non-zero status only after that cleanup
+	server := newServer(cfg, r)
+	failed := false
+	if err := runServer(server, cfg.TLSCertFile, cfg.TLSKeyFile, cfg.ShutdownTimeout); err != nil {
+		slog.Error("failed running merch store service", "error", err)
+		failed = true
+	}
+	flushCtx, cancel := context.WithTimeout(ctx, cfg.ShutdownTimeout)
+	defer cancel()
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Error("failed to flush traces", "error", err)
 	}
+	if err := sqlDB.Close(); err != nil {
+		slog.Error("failed to close the database connection pool", "error", err)
+		failed = true
+	}
+	if failed {
+		cancel()
+		os.Exit(1)
+	}
+	slog.Info("merch store service stopped")
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
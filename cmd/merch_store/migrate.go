@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ners1us/merch_store/internal/migration"
+)
+
+// runMigrate handles `merch_store migrate up|down [N]|down-to V|status`
+func runMigrate(migrator *migration.Migrator, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: merch_store migrate up|down [N]|down-to V|status")
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		for _, m := range applied {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+			steps = n
+		}
+		reverted, err := migrator.Down(steps)
+		for _, m := range reverted {
+			fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		}
+		return err
+	case "down-to":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: merch_store migrate down-to V")
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		reverted, err := migrator.DownTo(version)
+		for _, m := range reverted {
+			fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			if s.AppliedAt != nil {
+				fmt.Printf("%04d_%s\tapplied %s\n", s.Version, s.Name, s.AppliedAt.Format("2006-01-02 15:04:05"))
+			} else {
+				fmt.Printf("%04d_%s\tpending\n", s.Version, s.Name)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+}
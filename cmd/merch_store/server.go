@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ners1us/merch_store/internal/config"
+)
+
+func newServer(cfg *config.Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+}
+
+// runServer serves until SIGTERM or SIGINT, then stops accepting connections and waits for in-flight requests
+// for at most shutdownTimeout. TLS is used when both the certificate and the key file are set
+func runServer(server *http.Server, certFile, keyFile string, shutdownTimeout time.Duration) error {
+	if (certFile == "") != (keyFile == "") {
+		return fmt.Errorf("both TLS_CERT_FILE and TLS_KEY_FILE must be set to enable TLS")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	slog.Info("merch store service started", "addr", server.Addr, "tls", certFile != "")
+	serveErr := make(chan error, 1)
+	go func() {
+		if certFile != "" {
+			serveErr <- server.ListenAndServeTLS(certFile, keyFile)
+		} else {
+			serveErr <- server.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+	slog.Info("shutting down, waiting for in-flight requests", "timeout", shutdownTimeout.String())
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		// Requests still running past the deadline are cut off, so they don't outlive the connection pool
+		_ = server.Close()
+		return fmt.Errorf("failed to drain in-flight requests: %w", err)
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
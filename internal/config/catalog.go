@@ -0,0 +1,35 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ners1us/merch_store/internal/model"
+	"os"
+)
+
+func LoadCatalog(path string) ([]model.Merch, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var catalog []model.Merch
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("invalid catalog file %s: %w", path, err)
+	}
+
+	names := make(map[string]bool, len(catalog))
+	for _, merch := range catalog {
+		if merch.Name == "" || merch.Price <= 0 {
+			return nil, fmt.Errorf("invalid catalog item %q with price %d", merch.Name, merch.Price)
+		}
+		if merch.Stock != nil && *merch.Stock < 0 {
+			return nil, fmt.Errorf("invalid catalog item %q with stock %d", merch.Name, *merch.Stock)
+		}
+		if names[merch.Name] {
+			return nil, fmt.Errorf("duplicate catalog item %q", merch.Name)
+		}
+		names[merch.Name] = true
+	}
+	return catalog, nil
+}
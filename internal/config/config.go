@@ -1,18 +1,75 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type Config struct {
-	DbUrl     string
-	JWTSecret string
-	Port      string
+	DbUrl             string
+	JWTSecret         string
+	JWTAlgorithm      string
+	JWTSigningKey     string
+	JWTSigningKeyID   string
+	JWTVerifyingKeys  []string
+	Port              string
+	IdempotencyTTL    time.Duration
+	AdminUsername     string
+	AdminPassword     string
+	CatalogFile       string
+	AutoRegister      bool
+	InviteCodes       []string
+	AllowedUsers      []string
+	AccessTTL         time.Duration
+	RefreshTTL        time.Duration
+	RefundWindow      time.Duration
+	RequestTimeout    time.Duration
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration
+	TLSCertFile       string
+	TLSKeyFile        string
+	LogLevel          string
+	LogFormat         string
+	SlowQueryTime     time.Duration
+	TracesExporter    string
 }
 
 func InitConfig() *Config {
 	return &Config{
-		DbUrl:     getEnv("DB_URL"),
-		JWTSecret: getEnv("JWT_SECRET"),
-		Port:      getEnv("PORT"),
+		DbUrl:             getEnv("DB_URL"),
+		JWTSecret:         getEnv("JWT_SECRET"),
+		JWTAlgorithm:      getEnvDefault("JWT_ALGORITHM", "HS256"),
+		JWTSigningKey:     getEnv("JWT_SIGNING_KEY_FILE"),
+		JWTSigningKeyID:   getEnv("JWT_SIGNING_KEY_ID"),
+		JWTVerifyingKeys:  getEnvList("JWT_VERIFICATION_KEY_FILES"),
+		Port:              getEnv("PORT"),
+		IdempotencyTTL:    getEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		AdminUsername:     getEnv("ADMIN_USERNAME"),
+		AdminPassword:     getEnv("ADMIN_PASSWORD"),
+		CatalogFile:       getEnvDefault("CATALOG_FILE", "catalog.json"),
+		AutoRegister:      getEnvBool("AUTO_REGISTER", true),
+		InviteCodes:       getEnvList("REGISTRATION_INVITE_CODES"),
+		AllowedUsers:      getEnvList("REGISTRATION_ALLOWED_USERS"),
+		AccessTTL:         getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTTL:        getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		RefundWindow:      getEnvDuration("REFUND_WINDOW", 24*time.Hour),
+		RequestTimeout:    getEnvDuration("REQUEST_TIMEOUT", 5*time.Second),
+		ReadTimeout:       getEnvDuration("HTTP_READ_TIMEOUT", 10*time.Second),
+		ReadHeaderTimeout: getEnvDuration("HTTP_READ_HEADER_TIMEOUT", 5*time.Second),
+		WriteTimeout:      getEnvDuration("HTTP_WRITE_TIMEOUT", 15*time.Second),
+		IdleTimeout:       getEnvDuration("HTTP_IDLE_TIMEOUT", time.Minute),
+		ShutdownTimeout:   getEnvDuration("SHUTDOWN_TIMEOUT", 20*time.Second),
+		TLSCertFile:       getEnv("TLS_CERT_FILE"),
+		TLSKeyFile:        getEnv("TLS_KEY_FILE"),
+		LogLevel:          getEnvDefault("LOG_LEVEL", "info"),
+		LogFormat:         getEnvDefault("LOG_FORMAT", "json"),
+		SlowQueryTime:     getEnvDuration("SLOW_QUERY_THRESHOLD", 200*time.Millisecond),
+		TracesExporter:    getEnvDefault("OTEL_TRACES_EXPORTER", "none"),
 	}
 }
 
@@ -20,3 +77,36 @@ func getEnv(key string) string {
 	value, _ := os.LookupEnv(key)
 	return value
 }
+
+func getEnvDefault(key, defaultValue string) string {
+	if value := getEnv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(getEnv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(getEnv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
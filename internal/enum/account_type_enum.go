@@ -0,0 +1,12 @@
+package enum
+
+type AccountType string
+
+const (
+	AccountUser   AccountType = "user"
+	AccountSystem AccountType = "system"
+)
+
+func (at AccountType) String() string {
+	return string(at)
+}
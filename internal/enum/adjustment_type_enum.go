@@ -0,0 +1,12 @@
+package enum
+
+type AdjustmentType string
+
+const (
+	AdjustmentCredit AdjustmentType = "credit"
+	AdjustmentDebit  AdjustmentType = "debit"
+)
+
+func (at AdjustmentType) String() string {
+	return string(at)
+}
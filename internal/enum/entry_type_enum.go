@@ -0,0 +1,16 @@
+package enum
+
+type EntryType string
+
+const (
+	EntrySignupBonus    EntryType = "signup_bonus"
+	EntryOpeningBalance EntryType = "opening_balance"
+	EntryTransfer       EntryType = "transfer"
+	EntryPurchase       EntryType = "purchase"
+	EntryRefund         EntryType = "refund"
+	EntryAdjustment     EntryType = "adjustment"
+)
+
+func (et EntryType) String() string {
+	return string(et)
+}
@@ -1,30 +1,119 @@
 package enum
 
-type ErrorType string
-
-const (
-	ErrInsufficientMoney        ErrorType = "недостаточно монет"
-	ErrReceiverNotFound         ErrorType = "пользователь к переводу не нашелся"
-	ErrUserNotAuthorized        ErrorType = "пользователь не авторизован"
-	ErrWrongReqFormat           ErrorType = "неверный формат запроса"
-	ErrCoinsInappropriateAmount ErrorType = "количество монет должно быть больше нуля"
-	ErrReceivingCoinsInfo       ErrorType = "ошибка получения информации о монетах"
-	ErrInvalidToken             ErrorType = "неверный или просроченный токен"
-	ErrNoUsernameAndPassword    ErrorType = "имя пользователя и пароль обязательны"
-	ErrReceivingTransferHistory ErrorType = "ошибка получения истории переводов"
-	ErrReceivingPurchaseHistory ErrorType = "ошибка получения информации о покупках"
-	ErrBuyWithInsufficientMoney ErrorType = "недостаточно монет для покупки"
-	ErrItemNotFound             ErrorType = "товар не найден"
-	ErrNotProvidedItem          ErrorType = "не указан товар для покупки"
-	ErrGeneratingToken          ErrorType = "ошибка генерации токена"
-	ErrWrongCredentials         ErrorType = "неверное имя пользователя или пароль"
-	ErrInternalServer           ErrorType = "ошибка сервера"
-	ErrCreatingUser             ErrorType = "ошибка создания пользователя"
-	ErrNoAuthToken              ErrorType = "нет токена авторизации"
-	ErrWrongTokenFormat         ErrorType = "неверный формат токена"
-	ErrEqualReceivers           ErrorType = "получатели должны отличаться друг от друга"
+import (
+	"errors"
+	"net/http"
 )
 
+// ErrorType is a domain error with a stable machine-readable code and the HTTP status it is reported with.
+// Texts shown to clients are looked up by the code in the i18n message catalogs
+type ErrorType struct {
+	code   string
+	status int
+}
+
+var errorTypes []ErrorType
+
+func newErrorType(code string, status int) ErrorType {
+	errorType := ErrorType{code: code, status: status}
+	errorTypes = append(errorTypes, errorType)
+	return errorType
+}
+
+var (
+	ErrInsufficientMoney        = newErrorType("insufficient_money", http.StatusBadRequest)
+	ErrReceiverNotFound         = newErrorType("receiver_not_found", http.StatusNotFound)
+	ErrUserNotAuthorized        = newErrorType("user_not_authorized", http.StatusUnauthorized)
+	ErrWrongReqFormat           = newErrorType("wrong_request_format", http.StatusBadRequest)
+	ErrCoinsInappropriateAmount = newErrorType("inappropriate_amount", http.StatusBadRequest)
+	ErrReceivingCoinsInfo       = newErrorType("receiving_coins_info", http.StatusInternalServerError)
+	ErrInvalidToken             = newErrorType("invalid_token", http.StatusUnauthorized)
+	ErrNoUsernameAndPassword    = newErrorType("no_username_and_password", http.StatusBadRequest)
+	ErrReceivingTransferHistory = newErrorType("receiving_transfer_history", http.StatusInternalServerError)
+	ErrReceivingPurchaseHistory = newErrorType("receiving_purchase_history", http.StatusInternalServerError)
+	ErrBuyWithInsufficientMoney = newErrorType("buy_with_insufficient_money", http.StatusBadRequest)
+	ErrItemNotFound             = newErrorType("item_not_found", http.StatusNotFound)
+	ErrNotProvidedItem          = newErrorType("item_not_provided", http.StatusBadRequest)
+	ErrGeneratingToken          = newErrorType("generating_token", http.StatusInternalServerError)
+	ErrWrongCredentials         = newErrorType("wrong_credentials", http.StatusUnauthorized)
+	ErrInternalServer           = newErrorType("internal_server_error", http.StatusInternalServerError)
+	ErrCreatingUser             = newErrorType("creating_user", http.StatusInternalServerError)
+	ErrNoAuthToken              = newErrorType("no_auth_token", http.StatusUnauthorized)
+	ErrWrongTokenFormat         = newErrorType("wrong_token_format", http.StatusUnauthorized)
+	ErrEqualReceivers           = newErrorType("equal_receivers", http.StatusBadRequest)
+	ErrWrongIdempotencyKey      = newErrorType("wrong_idempotency_key", http.StatusBadRequest)
+	ErrIdempotencyKeyReused     = newErrorType("idempotency_key_reused", http.StatusUnprocessableEntity)
+	ErrIdempotencyKeyInProgress = newErrorType("idempotency_key_in_progress", http.StatusConflict)
+	ErrItemAlreadyExists        = newErrorType("item_already_exists", http.StatusConflict)
+	ErrInappropriatePrice       = newErrorType("inappropriate_price", http.StatusBadRequest)
+	ErrReceivingCatalog         = newErrorType("receiving_catalog", http.StatusInternalServerError)
+	ErrForbidden                = newErrorType("forbidden", http.StatusForbidden)
+	ErrOutOfStock               = newErrorType("out_of_stock", http.StatusConflict)
+	ErrInappropriateStock       = newErrorType("inappropriate_stock", http.StatusBadRequest)
+	ErrEmptyOrder               = newErrorType("empty_order", http.StatusBadRequest)
+	ErrInappropriateQuantity    = newErrorType("inappropriate_quantity", http.StatusBadRequest)
+	ErrUserAlreadyExists        = newErrorType("user_already_exists", http.StatusConflict)
+	ErrInvalidUsername          = newErrorType("invalid_username", http.StatusBadRequest)
+	ErrWeakPassword             = newErrorType("weak_password", http.StatusBadRequest)
+	ErrRegistrationForbidden    = newErrorType("registration_forbidden", http.StatusForbidden)
+	ErrInvalidRefreshToken      = newErrorType("invalid_refresh_token", http.StatusUnauthorized)
+	ErrRefreshTokenReused       = newErrorType("refresh_token_reused", http.StatusUnauthorized)
+	ErrTokenRevoked             = newErrorType("token_revoked", http.StatusUnauthorized)
+	ErrUserNotFound             = newErrorType("user_not_found", http.StatusNotFound)
+	ErrNoAdjustmentReason       = newErrorType("no_adjustment_reason", http.StatusBadRequest)
+	ErrReceivingAdjustments     = newErrorType("receiving_adjustments", http.StatusInternalServerError)
+	ErrUnbalancedEntry          = newErrorType("unbalanced_entry", http.StatusInternalServerError)
+	ErrReceivingHistory         = newErrorType("receiving_history", http.StatusInternalServerError)
+	ErrInvalidHistoryFilter     = newErrorType("invalid_history_filter", http.StatusBadRequest)
+	ErrInvalidCursor            = newErrorType("invalid_cursor", http.StatusBadRequest)
+	ErrInappropriateLimit       = newErrorType("inappropriate_limit", http.StatusBadRequest)
+	ErrPurchaseNotFound         = newErrorType("purchase_not_found", http.StatusNotFound)
+	ErrAlreadyRefunded          = newErrorType("already_refunded", http.StatusConflict)
+	ErrRefundWindowExpired      = newErrorType("refund_window_expired", http.StatusForbidden)
+	ErrRefundUnavailable        = newErrorType("refund_unavailable", http.StatusConflict)
+	ErrUnsupportedLocale        = newErrorType("unsupported_locale", http.StatusBadRequest)
+	ErrRequestTimeout           = newErrorType("request_timeout", http.StatusGatewayTimeout)
+)
+
+// ErrorTypes lists every declared error type
+func ErrorTypes() []ErrorType {
+	return append([]ErrorType(nil), errorTypes...)
+}
+
 func (et ErrorType) Error() string {
-	return string(et)
+	return et.code
+}
+
+func (et ErrorType) Code() string {
+	return et.code
+}
+
+func (et ErrorType) Status() int {
+	return et.status
+}
+
+// Wrap keeps the cause of the error for logs, errors.Is matches both the error type and the cause.
+// A cause that already is a domain error, such as a mapped constraint violation, is returned as is
+func (et ErrorType) Wrap(cause error) error {
+	if cause == nil {
+		return et
+	}
+	var domainErr ErrorType
+	if errors.As(cause, &domainErr) {
+		return cause
+	}
+	return &wrappedError{errorType: et, cause: cause}
+}
+
+type wrappedError struct {
+	errorType ErrorType
+	cause     error
+}
+
+func (we *wrappedError) Error() string {
+	return we.errorType.Error() + ": " + we.cause.Error()
+}
+
+func (we *wrappedError) Unwrap() []error {
+	return []error{we.errorType, we.cause}
 }
@@ -0,0 +1,12 @@
+package enum
+
+type Locale string
+
+const (
+	LocaleRu Locale = "ru"
+	LocaleEn Locale = "en"
+)
+
+func (l Locale) String() string {
+	return string(l)
+}
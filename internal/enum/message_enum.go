@@ -1,12 +1,21 @@
 package enum
 
+// MessageType is the stable code of a success message, its text comes from the i18n message catalogs
 type MessageType string
 
 const (
-	SuccessfulTransfer MessageType = "перевод выполнен успешно"
-	SuccessfulPurchase MessageType = "покупка прошла успешно"
+	SuccessfulTransfer     MessageType = "successful_transfer"
+	SuccessfulPurchase     MessageType = "successful_purchase"
+	SuccessfulRetire       MessageType = "successful_retire"
+	SuccessfulLogout       MessageType = "successful_logout"
+	SuccessfulLocaleChange MessageType = "successful_locale_change"
 )
 
+// MessageTypes lists every declared message type
+func MessageTypes() []MessageType {
+	return []MessageType{SuccessfulTransfer, SuccessfulPurchase, SuccessfulRetire, SuccessfulLogout, SuccessfulLocaleChange}
+}
+
 func (mt MessageType) String() string {
 	return string(mt)
 }
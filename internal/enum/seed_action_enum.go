@@ -0,0 +1,15 @@
+package enum
+
+type SeedAction string
+
+const (
+	SeedCreate      SeedAction = "create"
+	SeedUpdatePrice SeedAction = "update-price"
+	SeedKeepPrice   SeedAction = "keep-price"
+	SeedSkipRetired SeedAction = "skip-retired"
+	SeedUnchanged   SeedAction = "unchanged"
+)
+
+func (sa SeedAction) String() string {
+	return string(sa)
+}
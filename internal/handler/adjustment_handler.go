@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/service"
+	"net/http"
+	"strconv"
+)
+
+type AdjustmentHandler struct {
+	adjustmentService service.AdjustmentService
+}
+
+func NewAdjustmentHandler(adjustmentService service.AdjustmentService) *AdjustmentHandler {
+	return &AdjustmentHandler{adjustmentService: adjustmentService}
+}
+
+func (ah *AdjustmentHandler) HandleCredit(c *gin.Context) {
+	ah.handleAdjustment(c, enum.AdjustmentCredit)
+}
+
+func (ah *AdjustmentHandler) HandleDebit(c *gin.Context) {
+	ah.handleAdjustment(c, enum.AdjustmentDebit)
+}
+
+func (ah *AdjustmentHandler) handleAdjustment(c *gin.Context, adjustmentType enum.AdjustmentType) {
+	adminIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.Error(enum.ErrUserNotAuthorized)
+		return
+	}
+	adminID, _ := strconv.Atoi(adminIDStr.(string))
+
+	var req model.AdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(enum.ErrWrongReqFormat)
+		return
+	}
+
+	adjustment, err := ah.adjustmentService.AdjustCoins(c.Request.Context(), adminID, c.Param("username"), adjustmentType, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, adjustment)
+}
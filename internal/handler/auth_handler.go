@@ -19,19 +19,78 @@ func NewAuthHandler(authService service.AuthService) *AuthHandler {
 func (ah *AuthHandler) HandleAuth(c *gin.Context) {
 	var req model.AuthRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": enum.ErrWrongReqFormat.Error()})
+		c.Error(enum.ErrWrongReqFormat)
 		return
 	}
 	if req.Username == "" || req.Password == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": enum.ErrNoUsernameAndPassword.Error()})
+		c.Error(enum.ErrNoUsernameAndPassword)
 		return
 	}
 
-	token, err := ah.authService.Authenticate(req.Username, req.Password)
+	response, err := ah.authService.Authenticate(c.Request.Context(), req.Username, req.Password)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, model.AuthResponse{Token: token})
+	c.JSON(http.StatusOK, response)
+}
+
+func (ah *AuthHandler) HandleRegister(c *gin.Context) {
+	var req model.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(enum.ErrWrongReqFormat)
+		return
+	}
+	if req.Username == "" || req.Password == "" {
+		c.Error(enum.ErrNoUsernameAndPassword)
+		return
+	}
+
+	response, err := ah.authService.Register(c.Request.Context(), req.Username, req.Password, req.InviteCode)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (ah *AuthHandler) HandleRefresh(c *gin.Context) {
+	var req model.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(enum.ErrWrongReqFormat)
+		return
+	}
+
+	response, err := ah.authService.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (ah *AuthHandler) HandleLogout(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		c.Error(enum.ErrUserNotAuthorized)
+		return
+	}
+
+	var req model.RefreshRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(enum.ErrWrongReqFormat)
+			return
+		}
+	}
+
+	if err := ah.authService.Logout(c.Request.Context(), claims.(*model.Claims), req.RefreshToken); err != nil {
+		c.Error(err)
+		return
+	}
+
+	respondMessage(c, enum.SuccessfulLogout)
 }
@@ -4,7 +4,6 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/ners1us/merch_store/internal/enum"
 	"github.com/ners1us/merch_store/internal/service"
-	"net/http"
 	"strconv"
 )
 
@@ -19,26 +18,26 @@ func NewBuyHandler(merchService service.MerchService) *BuyHandler {
 func (bh *BuyHandler) HandleBuy(c *gin.Context) {
 	userIDStr, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": enum.ErrUserNotAuthorized.Error()})
+		c.Error(enum.ErrUserNotAuthorized)
 		return
 	}
 	userID, err := strconv.Atoi(userIDStr.(string))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		c.Error(err)
 		return
 	}
 
 	item := c.Param("item")
 	if item == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": enum.ErrNotProvidedItem.Error()})
+		c.Error(enum.ErrNotProvidedItem)
 		return
 	}
 
-	err = bh.merchService.BuyMerch(userID, item)
+	_, err = bh.merchService.BuyMerch(c.Request.Context(), userID, item)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": enum.SuccessfulPurchase.String()})
+	respondMessage(c, enum.SuccessfulPurchase)
 }
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/service"
+	"net/http"
+)
+
+type CatalogHandler struct {
+	catalogService service.CatalogService
+}
+
+func NewCatalogHandler(catalogService service.CatalogService) *CatalogHandler {
+	return &CatalogHandler{catalogService: catalogService}
+}
+
+func (ch *CatalogHandler) HandleListMerch(c *gin.Context) {
+	merch, err := ch.catalogService.ListMerch(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, merch)
+}
+
+func (ch *CatalogHandler) HandleAddMerch(c *gin.Context) {
+	var req model.MerchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(enum.ErrWrongReqFormat)
+		return
+	}
+
+	merch, err := ch.catalogService.AddMerch(c.Request.Context(), c.Param("name"), req.Price, req.Stock)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, merch)
+}
+
+func (ch *CatalogHandler) HandleUpdateMerch(c *gin.Context) {
+	var req model.MerchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(enum.ErrWrongReqFormat)
+		return
+	}
+
+	merch, err := ch.catalogService.UpdateMerch(c.Request.Context(), c.Param("name"), req.Price, req.Stock)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, merch)
+}
+
+func (ch *CatalogHandler) HandleRetireMerch(c *gin.Context) {
+	if err := ch.catalogService.RetireMerch(c.Request.Context(), c.Param("name")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	respondMessage(c, enum.SuccessfulRetire)
+}
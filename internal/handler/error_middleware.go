@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/i18n"
+	"github.com/ners1us/merch_store/internal/model"
+	"log/slog"
+	"net/http"
+)
+
+const problemContentType = "application/problem+json"
+
+// ErrorMiddleware renders the error a handler attached with c.Error as problem details.
+// Every error is logged with its cause, errors that are not domain errors are reported as a generic internal error
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		renderError(c)
+	}
+}
+
+func renderError(c *gin.Context) {
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+	err := c.Errors.Last().Err
+
+	var errorType enum.ErrorType
+	if errors.Is(err, context.DeadlineExceeded) {
+		errorType = enum.ErrRequestTimeout
+	} else if !errors.As(err, &errorType) {
+		errorType = enum.ErrInternalServer
+	}
+	// The error keeps the cause wrapped by the service, clients only get the text of the error type
+	if errorType.Status() >= http.StatusInternalServerError {
+		slog.ErrorContext(c.Request.Context(), "request failed", "code", errorType.Code(), "error", err)
+	} else {
+		slog.WarnContext(c.Request.Context(), "request rejected", "code", errorType.Code(), "error", err)
+	}
+
+	detail := i18n.Error(requestLocale(c), errorType)
+	c.Header("Content-Type", problemContentType)
+	c.JSON(errorType.Status(), model.Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(errorType.Status()),
+		Status:   errorType.Status(),
+		Detail:   detail,
+		Instance: c.Request.URL.Path,
+		Code:     errorType.Code(),
+		Error:    detail,
+	})
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/service"
+	"net/http"
+	"strconv"
+)
+
+type HistoryHandler struct {
+	historyService service.HistoryService
+}
+
+func NewHistoryHandler(historyService service.HistoryService) *HistoryHandler {
+	return &HistoryHandler{historyService: historyService}
+}
+
+func (hh *HistoryHandler) HandleHistory(c *gin.Context) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.Error(enum.ErrUserNotAuthorized)
+		return
+	}
+	userID, err := strconv.Atoi(userIDStr.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req model.HistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(enum.ErrWrongReqFormat)
+		return
+	}
+
+	page, err := hh.historyService.GetHistory(c.Request.Context(), userID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/service"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (rr *responseRecorder) Write(data []byte) (int, error) {
+	rr.body.Write(data)
+	return rr.ResponseWriter.Write(data)
+}
+
+func (rr *responseRecorder) WriteString(s string) (int, error) {
+	rr.body.WriteString(s)
+	return rr.ResponseWriter.WriteString(s)
+}
+
+func IdempotencyMiddleware(idempotencyService service.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		userIDStr, exists := c.Get("user_id")
+		if !exists {
+			c.Error(enum.ErrUserNotAuthorized)
+			c.Abort()
+			return
+		}
+		userID, _ := strconv.Atoi(userIDStr.(string))
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.Error(enum.ErrWrongReqFormat)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+		hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		stored, err := idempotencyService.Begin(c.Request.Context(), userID, key, requestHash)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if stored != nil {
+			contentType := stored.ContentType
+			if contentType == "" {
+				contentType = "application/json; charset=utf-8"
+			}
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(stored.StatusCode, contentType, stored.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		// The outcome is recorded even if the client has gone away meanwhile, otherwise the key stays in progress
+		ctx := context.WithoutCancel(c.Request.Context())
+		completed := false
+		// Releasing in a defer frees the key for a retry when the request fails with a server error or panics
+		defer func() {
+			if !completed {
+				_ = idempotencyService.Release(ctx, userID, key)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		// Errors are rendered here rather than by ErrorMiddleware, so the stored response includes them
+		renderError(c)
+
+		if recorder.Status() >= http.StatusInternalServerError {
+			return
+		}
+		err = idempotencyService.Complete(ctx, userID, key, recorder.Status(), recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+		completed = err == nil
+	}
+}
@@ -19,20 +19,55 @@ func NewInfoHandler(userService service.UserService) *InfoHandler {
 func (ih *InfoHandler) HandleInfo(c *gin.Context) {
 	userIDStr, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": enum.ErrUserNotAuthorized.Error()})
+		c.Error(enum.ErrUserNotAuthorized)
 		return
 	}
 	userID, err := strconv.Atoi(userIDStr.(string))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID"})
+		c.Error(err)
 		return
 	}
 
-	info, err := ih.userService.GetUserInfo(userID)
+	limit, err := historyLimit(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	info, err := ih.userService.GetUserInfo(c.Request.Context(), userID, limit)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, info)
 }
+
+func (ih *InfoHandler) HandleUserInfo(c *gin.Context) {
+	limit, err := historyLimit(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	info, err := ih.userService.GetUserInfoByUsername(c.Request.Context(), c.Param("username"), limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, info)
+}
+
+// historyLimit reads the optional limit query parameter, 0 means the whole history
+func historyLimit(c *gin.Context) (int, error) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 0 {
+		return 0, enum.ErrInappropriateLimit
+	}
+	return limit, nil
+}
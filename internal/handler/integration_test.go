@@ -3,27 +3,54 @@ package handler
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"github.com/ners1us/merch_store/internal/config"
 	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/keys"
+	"github.com/ners1us/merch_store/internal/logging"
+	"github.com/ners1us/merch_store/internal/metrics"
+	"github.com/ners1us/merch_store/internal/migration"
 	"github.com/ners1us/merch_store/internal/model"
 	"github.com/ners1us/merch_store/internal/repository"
 	"github.com/ners1us/merch_store/internal/service"
+	"github.com/ners1us/merch_store/internal/tracing"
 	"github.com/stretchr/testify/assert"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"io"
 	"log"
+	"log/slog"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 var container testcontainers.Container
 var db *gorm.DB
+var migrator *migration.Migrator
 
 func TestMain(m *testing.M) {
 	ctx := context.Background()
@@ -60,8 +87,15 @@ func TestMain(m *testing.M) {
 		log.Fatalf("Не удалось подключиться к базе данных: %s", err)
 	}
 
-	err = db.AutoMigrate(&model.User{}, &model.Merch{}, &model.Purchase{}, &model.CoinTransfer{})
+	sqlDB, err := db.DB()
 	if err != nil {
+		log.Fatalf("Не удалось получить соединение с базой данных: %s", err)
+	}
+	migrator, err = migration.NewMigrator(sqlDB)
+	if err != nil {
+		log.Fatalf("Не удалось загрузить миграции: %s", err)
+	}
+	if _, err := migrator.Up(); err != nil {
 		log.Fatalf("Ошибка миграции: %s", err)
 	}
 
@@ -74,35 +108,114 @@ func TestMain(m *testing.M) {
 }
 
 func setupRouter() *gin.Engine {
-	jwtSecret := []byte("elaborate_secret")
-	userRepo := repository.NewUserRepository(db)
-	merchRepo := repository.NewMerchRepository(db)
-	purchaseRepo := repository.NewPurchaseRepository(db)
-	transferRepo := repository.NewCoinTransferRepository(db)
+	return newTestRouter(db, keys.NewHMACKeySet([]byte("elaborate_secret")), metrics.New(), noop.NewTracerProvider())
+}
+
+func newTestRouter(database *gorm.DB, keySet *keys.KeySet, appMetrics *metrics.Metrics, tracerProvider trace.TracerProvider) *gin.Engine {
+	userRepo := repository.NewUserRepository(database)
+	merchRepo := repository.NewMerchRepository(database)
+	purchaseRepo := repository.NewPurchaseRepository(database)
+	transferRepo := repository.NewCoinTransferRepository(database)
+	idempotencyRepo := repository.NewIdempotencyKeyRepository(database)
+	orderRepo := repository.NewOrderRepository(database)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(database)
+	revokedTokenRepo := repository.NewRevokedTokenRepository(database)
+	adjustmentRepo := repository.NewCoinAdjustmentRepository(database)
+	ledgerRepo := repository.NewLedgerRepository(database)
 
-	authService := service.NewAuthService(userRepo, jwtSecret)
-	userService := service.NewUserService(userRepo, purchaseRepo, transferRepo)
-	merchService := service.NewMerchService(userRepo, merchRepo, purchaseRepo)
-	transferService := service.NewTransferService(userRepo, transferRepo)
+	tokenService := service.NewTokenService(userRepo, refreshTokenRepo, revokedTokenRepo, keySet, time.Minute, time.Hour)
+	authService := service.NewAuthService(userRepo, ledgerRepo, tokenService, service.RegistrationPolicy{AutoRegister: true})
+	authService = service.NewAuthServiceWithMetrics(service.NewAuthServiceWithTracing(authService, tracerProvider), appMetrics)
+	if err := authService.EnsureAdmin(context.Background(), "admin", "adminpassw0rd"); err != nil {
+		log.Fatalf("Ошибка создания администратора: %s", err)
+	}
+	userService := service.NewUserServiceWithTracing(service.NewUserService(userRepo, purchaseRepo, transferRepo, adjustmentRepo), tracerProvider)
+	merchService := service.NewMerchService(userRepo, merchRepo, purchaseRepo, ledgerRepo)
+	merchService = service.NewMerchServiceWithMetrics(service.NewMerchServiceWithTracing(merchService, tracerProvider), appMetrics)
+	transferService := service.NewTransferService(userRepo, transferRepo, repository.NewLedgerRepository(database))
+	transferService = service.NewTransferServiceWithMetrics(service.NewTransferServiceWithTracing(transferService, tracerProvider), appMetrics)
+	adjustmentService := service.NewAdjustmentService(userRepo, adjustmentRepo, ledgerRepo)
+	historyService := service.NewHistoryService(ledgerRepo)
+	refundService := service.NewRefundService(userRepo, merchRepo, purchaseRepo, ledgerRepo, time.Hour)
+	orderService := service.NewOrderServiceWithMetrics(service.NewOrderService(userRepo, merchRepo, orderRepo, ledgerRepo), appMetrics)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo, time.Hour, 10*time.Second)
+	catalogService := service.NewCatalogService(merchRepo)
 
 	authHandler := NewAuthHandler(authService)
 	infoHandler := NewInfoHandler(userService)
+	localeHandler := NewLocaleHandler(userService)
 	buyHandler := NewBuyHandler(merchService)
 	sendCoinHandler := NewSendCoinHandler(transferService)
+	catalogHandler := NewCatalogHandler(catalogService)
+	orderHandler := NewOrderHandler(orderService)
+	adjustmentHandler := NewAdjustmentHandler(adjustmentService)
+	historyHandler := NewHistoryHandler(historyService)
+	refundHandler := NewRefundHandler(refundService)
+	jwksHandler := NewJWKSHandler(keySet)
+
+	authMiddleware := AuthMiddleware(tokenService)
+	adminMiddleware := RequireRole(enum.RoleAdmin)
 
-	router := gin.Default()
+	router := gin.New()
+	router.Use(TracingMiddleware(tracerProvider))
+	router.Use(RequestLogger())
+	router.Use(MetricsMiddleware(appMetrics))
+	router.Use(LocaleMiddleware(userService))
+	router.Use(ErrorMiddleware())
+	router.Use(RecoveryMiddleware())
+	router.Use(TimeoutMiddleware(5 * time.Second))
+	router.GET("/.well-known/jwks.json", jwksHandler.HandleJWKS)
+	router.GET("/metrics", MetricsHandler(appMetrics))
 	apiRoutes := router.Group("/api")
 	{
 		apiRoutes.POST("/auth", authHandler.HandleAuth)
-		apiRoutes.GET("/info", AuthMiddleware(jwtSecret), infoHandler.HandleInfo)
-		apiRoutes.POST("/sendCoin", AuthMiddleware(jwtSecret), sendCoinHandler.HandleSendCoin)
-		apiRoutes.GET("/buy/:item", AuthMiddleware(jwtSecret), buyHandler.HandleBuy)
+		apiRoutes.POST("/register", authHandler.HandleRegister)
+		apiRoutes.POST("/auth/refresh", authHandler.HandleRefresh)
+		apiRoutes.POST("/logout", authMiddleware, authHandler.HandleLogout)
+		apiRoutes.GET("/info", authMiddleware, infoHandler.HandleInfo)
+		apiRoutes.GET("/history", authMiddleware, historyHandler.HandleHistory)
+		apiRoutes.PUT("/locale", authMiddleware, localeHandler.HandleSetLocale)
+		apiRoutes.POST("/sendCoin", authMiddleware, IdempotencyMiddleware(idempotencyService), sendCoinHandler.HandleSendCoin)
+		apiRoutes.GET("/buy/:item", authMiddleware, IdempotencyMiddleware(idempotencyService), buyHandler.HandleBuy)
+		apiRoutes.POST("/orders", authMiddleware, IdempotencyMiddleware(idempotencyService), orderHandler.HandlePlaceOrder)
+		apiRoutes.POST("/purchases/:id/refund", authMiddleware, refundHandler.HandleRefund)
+		apiRoutes.GET("/admin/users/:username/info", authMiddleware, adminMiddleware, infoHandler.HandleUserInfo)
+		apiRoutes.POST("/admin/users/:username/credit", authMiddleware, adminMiddleware, adjustmentHandler.HandleCredit)
+		apiRoutes.POST("/admin/users/:username/debit", authMiddleware, adminMiddleware, adjustmentHandler.HandleDebit)
+		apiRoutes.GET("/merch", authMiddleware, catalogHandler.HandleListMerch)
+		apiRoutes.POST("/merch/:name", authMiddleware, adminMiddleware, catalogHandler.HandleAddMerch)
+		apiRoutes.PUT("/merch/:name", authMiddleware, adminMiddleware, catalogHandler.HandleUpdateMerch)
+		apiRoutes.DELETE("/merch/:name", authMiddleware, adminMiddleware, catalogHandler.HandleRetireMerch)
 	}
 	return router
 }
 
+type failingPurchaseRepository struct {
+	repository.PurchaseRepository
+}
+
+func (fpr *failingPurchaseRepository) Create(ctx context.Context, purchase *model.Purchase) error {
+	return errors.New("не удалось сохранить покупку")
+}
+
+func (fpr *failingPurchaseRepository) WithTx(tx *gorm.DB) repository.PurchaseRepository {
+	return &failingPurchaseRepository{PurchaseRepository: fpr.PurchaseRepository.WithTx(tx)}
+}
+
+type failingCoinTransferRepository struct {
+	repository.CoinTransferRepository
+}
+
+func (fctr *failingCoinTransferRepository) Create(ctx context.Context, transfer *model.CoinTransfer) error {
+	return errors.New("не удалось сохранить перевод")
+}
+
+func (fctr *failingCoinTransferRepository) WithTx(tx *gorm.DB) repository.CoinTransferRepository {
+	return &failingCoinTransferRepository{CoinTransferRepository: fctr.CoinTransferRepository.WithTx(tx)}
+}
+
 func clearDB() {
-	db.Exec("TRUNCATE TABLE coin_transfers, purchases, merches, users RESTART IDENTITY CASCADE")
+	db.Exec("TRUNCATE TABLE postings, journal_entries, ledger_accounts, coin_adjustments, revoked_tokens, refresh_tokens, idempotency_keys, coin_transfers, purchases, orders, merches, users RESTART IDENTITY CASCADE")
 }
 
 func performAuth(t *testing.T, serverURL, username, password string) string {
@@ -131,6 +244,31 @@ func performAuth(t *testing.T, serverURL, username, password string) string {
 	return authResponse.Token
 }
 
+func performRequest(t *testing.T, method, url, token string, body interface{}) *http.Response {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			t.Fatalf("Ошибка маршалинга запроса: %v", err)
+		}
+	}
+	request, err := http.NewRequest(method, url, bytes.NewBuffer(payload))
+	if err != nil {
+		t.Fatalf("Ошибка создания запроса: %v", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("Ошибка выполнения запроса %s %s: %v", method, url, err)
+	}
+	t.Cleanup(func() { response.Body.Close() })
+	return response
+}
+
 func TestBuyMerch(t *testing.T) {
 	// Arrange
 	clearDB()
@@ -143,7 +281,7 @@ func TestBuyMerch(t *testing.T) {
 		Price: 500,
 	}
 	db.Create(&merchItem)
-	token := performAuth(t, ts.URL, "ners1us", "thelongestpasswordever")
+	token := performAuth(t, ts.URL, "ners1us", "thelongestpassw0rdever")
 
 	// Act
 	request, err := http.NewRequest("GET", ts.URL+"/api/buy/t-shirt", nil)
@@ -166,7 +304,7 @@ func TestBuyMerch(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Ошибка декодирования ответа покупки: %v", err)
 	}
-	assert.Equal(t, enum.SuccessfulPurchase.String(), buyResponse["message"])
+	assert.Equal(t, enum.SuccessfulPurchase.String(), buyResponse["code"])
 
 	// Act
 	request, err = http.NewRequest("GET", ts.URL+"/api/info", nil)
@@ -200,8 +338,8 @@ func TestSendCoin(t *testing.T) {
 	ts := httptest.NewServer(router)
 	defer ts.Close()
 
-	tokenSender := performAuth(t, ts.URL, "johnnyBravo", "ilikepie")
-	tokenReceiver := performAuth(t, ts.URL, "darthVader", "iamlivingcorpse")
+	tokenSender := performAuth(t, ts.URL, "johnnyBravo", "ilikepie3")
+	tokenReceiver := performAuth(t, ts.URL, "darthVader", "iamlivingc0rpse")
 
 	sendCoinRequest := model.SendCoinRequest{
 		ToUser: "darthVader",
@@ -234,7 +372,7 @@ func TestSendCoin(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Ошибка декодирования ответа отправки монет: %v", err)
 	}
-	assert.Equal(t, enum.SuccessfulTransfer.String(), transferResponse["message"])
+	assert.Equal(t, enum.SuccessfulTransfer.String(), transferResponse["code"])
 
 	// Act
 	request, err = http.NewRequest("GET", ts.URL+"/api/info", nil)
@@ -278,3 +416,1365 @@ func TestSendCoin(t *testing.T) {
 	expectedReceiverCoins := 1200
 	assert.Equal(t, expectedReceiverCoins, infoReceiver.Coins)
 }
+
+func TestBuyMerchRollback(t *testing.T) {
+	// Arrange
+	clearDB()
+	userRepo := repository.NewUserRepository(db)
+	purchaseRepo := &failingPurchaseRepository{PurchaseRepository: repository.NewPurchaseRepository(db)}
+	merchService := service.NewMerchService(userRepo, repository.NewMerchRepository(db), purchaseRepo, repository.NewLedgerRepository(db))
+
+	db.Create(&model.Merch{Name: "hoody", Price: 300})
+	user := &model.User{Username: "rollbacker", Password: "hash", Coins: 1000}
+	db.Create(user)
+
+	// Act
+	_, err := merchService.BuyMerch(context.Background(), user.ID, "hoody")
+
+	// Assert
+	assert.Error(t, err)
+	var stored model.User
+	db.First(&stored, user.ID)
+	assert.Equal(t, 1000, stored.Coins)
+	var purchases int64
+	db.Model(&model.Purchase{}).Count(&purchases)
+	assert.Equal(t, int64(0), purchases)
+}
+
+func TestSendCoinRollback(t *testing.T) {
+	// Arrange
+	clearDB()
+	userRepo := repository.NewUserRepository(db)
+	transferRepo := &failingCoinTransferRepository{CoinTransferRepository: repository.NewCoinTransferRepository(db)}
+	transferService := service.NewTransferService(userRepo, transferRepo, repository.NewLedgerRepository(db))
+
+	sender := &model.User{Username: "sender", Password: "hash", Coins: 1000}
+	receiver := &model.User{Username: "receiver", Password: "hash", Coins: 1000}
+	db.Create(sender)
+	db.Create(receiver)
+
+	// Act
+	err := transferService.SendCoin(context.Background(), sender.ID, receiver.Username, 300)
+
+	// Assert
+	assert.Error(t, err)
+	var storedSender, storedReceiver model.User
+	db.First(&storedSender, sender.ID)
+	db.First(&storedReceiver, receiver.ID)
+	assert.Equal(t, 1000, storedSender.Coins)
+	assert.Equal(t, 1000, storedReceiver.Coins)
+}
+
+func TestConcurrentTransfersAndPurchases(t *testing.T) {
+	// Arrange
+	clearDB()
+	router := setupRouter()
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	merchItem := model.Merch{Name: "pen", Price: 10}
+	db.Create(&merchItem)
+	usernames := []string{"anna", "boris", "vera", "gleb", "dina"}
+	tokens := make([]string, len(usernames))
+	for i, username := range usernames {
+		tokens[i] = performAuth(t, ts.URL, username, "passw0rd")
+	}
+
+	// Act
+	const requestsCount = 300
+	const transferAmount = 50
+	var mu sync.Mutex
+	expectedCoins := make(map[string]int, len(usernames))
+	for _, username := range usernames {
+		expectedCoins[username] = 1000
+	}
+	var transfersSucceeded, purchasesSucceeded int
+	var wg sync.WaitGroup
+	for i := 0; i < requestsCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			from := i % len(usernames)
+			to := (from + 1 + i%(len(usernames)-1)) % len(usernames)
+			purchase := i%3 == 0
+			var request *http.Request
+			var err error
+			if purchase {
+				request, err = http.NewRequest("GET", ts.URL+"/api/buy/"+merchItem.Name, nil)
+			} else {
+				payload, _ := json.Marshal(model.SendCoinRequest{ToUser: usernames[to], Amount: transferAmount})
+				request, err = http.NewRequest("POST", ts.URL+"/api/sendCoin", bytes.NewBuffer(payload))
+				request.Header.Set("Content-Type", "application/json")
+			}
+			if err != nil {
+				t.Errorf("Ошибка создания запроса: %v", err)
+				return
+			}
+			request.Header.Set("Authorization", "Bearer "+tokens[from])
+			response, err := http.DefaultClient.Do(request)
+			if err != nil {
+				t.Errorf("Ошибка выполнения запроса: %v", err)
+				return
+			}
+			response.Body.Close()
+			if response.StatusCode != http.StatusOK {
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if purchase {
+				purchasesSucceeded++
+				expectedCoins[usernames[from]] -= merchItem.Price
+			} else {
+				transfersSucceeded++
+				expectedCoins[usernames[from]] -= transferAmount
+				expectedCoins[usernames[to]] += transferAmount
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	assert.Positive(t, transfersSucceeded)
+	assert.Positive(t, purchasesSucceeded)
+	var transfersCount int64
+	db.Model(&model.CoinTransfer{}).Count(&transfersCount)
+	assert.Equal(t, int64(transfersSucceeded), transfersCount)
+	for _, username := range usernames {
+		var user model.User
+		db.Where("username = ?", username).First(&user)
+		assert.Equal(t, expectedCoins[username], user.Coins, "баланс пользователя %s", username)
+	}
+	var totalCoins int64
+	db.Model(&model.User{}).Where("username IN ?", usernames).Select("coalesce(sum(coins), 0)").Scan(&totalCoins)
+	var purchasesCount int64
+	db.Model(&model.Purchase{}).Count(&purchasesCount)
+	assert.Equal(t, int64(purchasesSucceeded), purchasesCount)
+	assert.Equal(t, int64(len(usernames)*1000), totalCoins+purchasesCount*int64(merchItem.Price))
+	var negativeBalances int64
+	db.Model(&model.User{}).Where("coins < 0").Count(&negativeBalances)
+	assert.Zero(t, negativeBalances)
+}
+
+func TestSendCoinIdempotency(t *testing.T) {
+	// Arrange
+	clearDB()
+	router := setupRouter()
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	tokenSender := performAuth(t, ts.URL, "retrier", "passw0rd")
+	performAuth(t, ts.URL, "payee", "passw0rd")
+
+	sendCoin := func(amount int) *http.Response {
+		payload, err := json.Marshal(model.SendCoinRequest{ToUser: "payee", Amount: amount})
+		if err != nil {
+			t.Fatalf("Ошибка маршалинга запроса отправки монет: %v", err)
+		}
+		request, err := http.NewRequest("POST", ts.URL+"/api/sendCoin", bytes.NewBuffer(payload))
+		if err != nil {
+			t.Fatalf("Ошибка создания запроса отправки монет: %v", err)
+		}
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer "+tokenSender)
+		request.Header.Set("Idempotency-Key", "transfer-1")
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("Ошибка выполнения запроса отправки монет: %v", err)
+		}
+		defer response.Body.Close()
+		return response
+	}
+
+	// Act
+	first := sendCoin(100)
+	replay := sendCoin(100)
+	mismatch := sendCoin(150)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, first.StatusCode)
+	assert.Equal(t, http.StatusOK, replay.StatusCode)
+	assert.Equal(t, "true", replay.Header.Get("Idempotent-Replayed"))
+	assert.Equal(t, http.StatusUnprocessableEntity, mismatch.StatusCode)
+	var sender model.User
+	db.Where("username = ?", "retrier").First(&sender)
+	assert.Equal(t, 900, sender.Coins)
+	var transfers int64
+	db.Model(&model.CoinTransfer{}).Count(&transfers)
+	assert.Equal(t, int64(1), transfers)
+}
+
+func TestIdempotencyReplayAndRelease(t *testing.T) {
+	// Arrange
+	clearDB()
+	router := setupRouter()
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	token := performAuth(t, ts.URL, "retrier", "passw0rd")
+	var user model.User
+	db.Where("username = ?", "retrier").First(&user)
+
+	idempotencyService := service.NewIdempotencyService(repository.NewIdempotencyKeyRepository(db), time.Hour, 10*time.Second)
+	panicking := gin.New()
+	panicking.Use(ErrorMiddleware())
+	panicking.Use(RecoveryMiddleware())
+	panicking.POST("/panic", func(c *gin.Context) {
+		c.Set("user_id", strconv.Itoa(user.ID))
+	}, IdempotencyMiddleware(idempotencyService), func(c *gin.Context) {
+		panic("сбой обработчика")
+	})
+	panicServer := httptest.NewServer(panicking)
+	defer panicServer.Close()
+
+	send := func(url, token, key string, body interface{}) *http.Response {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("Ошибка маршалинга запроса: %v", err)
+		}
+		request, err := http.NewRequest("POST", url, bytes.NewBuffer(payload))
+		if err != nil {
+			t.Fatalf("Ошибка создания запроса: %v", err)
+		}
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer "+token)
+		request.Header.Set("Idempotency-Key", key)
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("Ошибка выполнения запроса %s: %v", url, err)
+		}
+		t.Cleanup(func() { response.Body.Close() })
+		return response
+	}
+
+	// Act
+	failed := send(ts.URL+"/api/sendCoin", token, "missing-payee", model.SendCoinRequest{ToUser: "ghost", Amount: 10})
+	replayed := send(ts.URL+"/api/sendCoin", token, "missing-payee", model.SendCoinRequest{ToUser: "ghost", Amount: 10})
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, failed.StatusCode)
+	assert.Equal(t, http.StatusNotFound, replayed.StatusCode)
+	assert.Equal(t, "true", replayed.Header.Get("Idempotent-Replayed"))
+	assert.Contains(t, failed.Header.Get("Content-Type"), "application/problem+json")
+	assert.Equal(t, failed.Header.Get("Content-Type"), replayed.Header.Get("Content-Type"))
+
+	// Act
+	crashed := send(panicServer.URL+"/panic", "", "crash", nil)
+	retried := send(panicServer.URL+"/panic", "", "crash", nil)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, crashed.StatusCode)
+	assert.Equal(t, http.StatusInternalServerError, retried.StatusCode)
+	var reserved int64
+	db.Model(&model.IdempotencyKey{}).Where("user_id = ? AND key = ?", user.ID, "crash").Count(&reserved)
+	assert.Equal(t, int64(0), reserved)
+}
+
+func TestCatalogManagement(t *testing.T) {
+	// Arrange
+	clearDB()
+	router := setupRouter()
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	adminToken := performAuth(t, ts.URL, "admin", "adminpassw0rd")
+	userToken := performAuth(t, ts.URL, "shopper", "passw0rd")
+
+	// Act
+	forbidden := performRequest(t, "POST", ts.URL+"/api/merch/sticker", userToken, model.MerchRequest{Price: 5})
+	created := performRequest(t, "POST", ts.URL+"/api/merch/sticker", adminToken, model.MerchRequest{Price: 5})
+	repriced := performRequest(t, "PUT", ts.URL+"/api/merch/sticker", adminToken, model.MerchRequest{Price: 15})
+	bought := performRequest(t, "GET", ts.URL+"/api/buy/sticker", userToken, nil)
+	retired := performRequest(t, "DELETE", ts.URL+"/api/merch/sticker", adminToken, nil)
+	boughtRetired := performRequest(t, "GET", ts.URL+"/api/buy/sticker", userToken, nil)
+	listed := performRequest(t, "GET", ts.URL+"/api/merch", userToken, nil)
+	info := performRequest(t, "GET", ts.URL+"/api/info", userToken, nil)
+
+	// Assert
+	assert.Equal(t, http.StatusForbidden, forbidden.StatusCode)
+	assert.Equal(t, http.StatusCreated, created.StatusCode)
+	assert.Equal(t, http.StatusOK, repriced.StatusCode)
+	assert.Equal(t, http.StatusOK, bought.StatusCode)
+	assert.Equal(t, http.StatusOK, retired.StatusCode)
+	assert.Equal(t, http.StatusNotFound, boughtRetired.StatusCode)
+
+	var catalog []model.Merch
+	if err := json.NewDecoder(listed.Body).Decode(&catalog); err != nil {
+		t.Fatalf("Ошибка декодирования каталога: %v", err)
+	}
+	assert.Empty(t, catalog)
+
+	var infoResponse model.InfoResponse
+	if err := json.NewDecoder(info.Body).Decode(&infoResponse); err != nil {
+		t.Fatalf("Ошибка декодирования ответа: %v", err)
+	}
+	assert.Equal(t, 985, infoResponse.Coins)
+	assert.Equal(t, []model.InventoryItem{{Type: "sticker", Quantity: 1}}, infoResponse.Inventory)
+}
+
+func TestUpdateMerchKeepsStock(t *testing.T) {
+	// Arrange
+	clearDB()
+	router := setupRouter()
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	adminToken := performAuth(t, ts.URL, "admin", "adminpassw0rd")
+	userToken := performAuth(t, ts.URL, "shopper", "passw0rd")
+	stock := 2
+	performRequest(t, "POST", ts.URL+"/api/merch/badge", adminToken, model.MerchRequest{Price: 10, Stock: &stock})
+	performRequest(t, "GET", ts.URL+"/api/buy/badge", userToken, nil)
+
+	// Act
+	repriced := performRequest(t, "PUT", ts.URL+"/api/merch/badge", adminToken, model.MerchRequest{Price: 12})
+	var afterReprice model.Merch
+	db.Where("name = ?", "badge").First(&afterReprice)
+	restock := 5
+	restocked := performRequest(t, "PUT", ts.URL+"/api/merch/badge", adminToken, model.MerchRequest{Price: 12, Stock: &restock})
+	var afterRestock model.Merch
+	db.Where("name = ?", "badge").First(&afterRestock)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, repriced.StatusCode)
+	assert.Equal(t, http.StatusOK, restocked.StatusCode)
+	assert.Equal(t, 12, afterReprice.Price)
+	if afterReprice.Stock == nil || afterRestock.Stock == nil {
+		t.Fatalf("Изменение цены сняло ограничение запаса товара")
+	}
+	assert.Equal(t, 1, *afterReprice.Stock)
+	assert.Equal(t, 5, *afterRestock.Stock)
+}
+
+func TestConcurrentPurchasesOfLastUnits(t *testing.T) {
+	// Arrange
+	clearDB()
+	router := setupRouter()
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	stock := 3
+	db.Create(&model.Merch{Name: "pink-hoody", Price: 500, Stock: &stock})
+	const buyersCount = 20
+	tokens := make([]string, buyersCount)
+	for i := range tokens {
+		tokens[i] = performAuth(t, ts.URL, fmt.Sprintf("buyer%d", i), "passw0rd")
+	}
+
+	// Act
+	statuses := make([]int, buyersCount)
+	var wg sync.WaitGroup
+	for i, token := range tokens {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			request, err := http.NewRequest("GET", ts.URL+"/api/buy/pink-hoody", nil)
+			if err != nil {
+				t.Errorf("Ошибка создания запроса: %v", err)
+				return
+			}
+			request.Header.Set("Authorization", "Bearer "+token)
+			response, err := http.DefaultClient.Do(request)
+			if err != nil {
+				t.Errorf("Ошибка выполнения запроса покупки: %v", err)
+				return
+			}
+			response.Body.Close()
+			statuses[i] = response.StatusCode
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	succeeded := 0
+	for _, status := range statuses {
+		if status == http.StatusOK {
+			succeeded++
+		}
+	}
+	assert.Equal(t, stock, succeeded)
+	var merch model.Merch
+	db.First(&merch, "name = ?", "pink-hoody")
+	assert.Equal(t, 0, *merch.Stock)
+	var purchases int64
+	db.Model(&model.Purchase{}).Count(&purchases)
+	assert.Equal(t, int64(stock), purchases)
+}
+
+func TestPlaceOrder(t *testing.T) {
+	// Arrange
+	clearDB()
+	router := setupRouter()
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	stock := 1
+	db.Create(&model.Merch{Name: "socks", Price: 10})
+	db.Create(&model.Merch{Name: "powerbank", Price: 200, Stock: &stock})
+	token := performAuth(t, ts.URL, "collector", "passw0rd")
+
+	// Act
+	placed := performRequest(t, "POST", ts.URL+"/api/orders", token, model.OrderRequest{Items: []model.OrderLine{
+		{Item: "socks", Quantity: 3},
+		{Item: "powerbank", Quantity: 1},
+	}})
+	outOfStock := performRequest(t, "POST", ts.URL+"/api/orders", token, model.OrderRequest{Items: []model.OrderLine{
+		{Item: "socks", Quantity: 2},
+		{Item: "powerbank", Quantity: 1},
+	}})
+	info := performRequest(t, "GET", ts.URL+"/api/info", token, nil)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, placed.StatusCode)
+	var order model.Order
+	if err := json.NewDecoder(placed.Body).Decode(&order); err != nil {
+		t.Fatalf("Ошибка декодирования заказа: %v", err)
+	}
+	assert.Equal(t, 230, order.Total)
+	assert.Len(t, order.Purchases, 2)
+
+	assert.Equal(t, http.StatusConflict, outOfStock.StatusCode)
+
+	var infoResponse model.InfoResponse
+	if err := json.NewDecoder(info.Body).Decode(&infoResponse); err != nil {
+		t.Fatalf("Ошибка декодирования ответа: %v", err)
+	}
+	assert.Equal(t, 770, infoResponse.Coins)
+	assert.ElementsMatch(t, []model.InventoryItem{{Type: "socks", Quantity: 3}, {Type: "powerbank", Quantity: 1}}, infoResponse.Inventory)
+	var orders int64
+	db.Model(&model.Order{}).Count(&orders)
+	assert.Equal(t, int64(1), orders)
+}
+
+func TestConcurrentOrdersWithReversedBaskets(t *testing.T) {
+	// Arrange
+	clearDB()
+	router := setupRouter()
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	stock := 1000
+	db.Create(&model.Merch{Name: "cup", Price: 1, Stock: &stock})
+	db.Create(&model.Merch{Name: "pen", Price: 1, Stock: &stock})
+	baskets := [][]model.OrderLine{
+		{{Item: "cup", Quantity: 1}, {Item: "pen", Quantity: 1}},
+		{{Item: "pen", Quantity: 1}, {Item: "cup", Quantity: 1}},
+	}
+	tokens := []string{
+		performAuth(t, ts.URL, "forward", "passw0rd"),
+		performAuth(t, ts.URL, "backward", "passw0rd"),
+	}
+
+	// Act
+	const ordersCount = 40
+	statuses := make([]int, ordersCount)
+	var wg sync.WaitGroup
+	for i := 0; i < ordersCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			payload, _ := json.Marshal(model.OrderRequest{Items: baskets[i%2]})
+			request, err := http.NewRequest("POST", ts.URL+"/api/orders", bytes.NewBuffer(payload))
+			if err != nil {
+				t.Errorf("Ошибка создания запроса: %v", err)
+				return
+			}
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set("Authorization", "Bearer "+tokens[i%2])
+			response, err := http.DefaultClient.Do(request)
+			if err != nil {
+				t.Errorf("Ошибка выполнения запроса: %v", err)
+				return
+			}
+			response.Body.Close()
+			statuses[i] = response.StatusCode
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	for i, status := range statuses {
+		assert.Equal(t, http.StatusCreated, status, "заказ %d", i)
+	}
+	var merches []model.Merch
+	db.Order("name").Find(&merches)
+	for _, merch := range merches {
+		assert.Equal(t, stock-ordersCount, *merch.Stock, "остаток %s", merch.Name)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	// Arrange
+	clearDB()
+	router := setupRouter()
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	request := model.RegisterRequest{Username: "registrant", Password: "str0ngpassword"}
+
+	// Act
+	registered := performRequest(t, "POST", ts.URL+"/api/register", "", request)
+	duplicate := performRequest(t, "POST", ts.URL+"/api/register", "", request)
+	weak := performRequest(t, "POST", ts.URL+"/api/register", "", model.RegisterRequest{Username: "weakling", Password: "123"})
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, registered.StatusCode)
+	var authResponse model.AuthResponse
+	if err := json.NewDecoder(registered.Body).Decode(&authResponse); err != nil {
+		t.Fatalf("Ошибка декодирования ответа регистрации: %v", err)
+	}
+	assert.NotEmpty(t, authResponse.Token)
+	assert.Equal(t, http.StatusConflict, duplicate.StatusCode)
+	assert.Equal(t, http.StatusBadRequest, weak.StatusCode)
+	token := performAuth(t, ts.URL, "registrant", "str0ngpassword")
+	assert.NotEmpty(t, token)
+}
+
+func TestRefreshAndLogout(t *testing.T) {
+	// Arrange
+	clearDB()
+	router := setupRouter()
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	login := performRequest(t, "POST", ts.URL+"/api/auth", "", model.AuthRequest{Username: "sessioner", Password: "passw0rd"})
+	var initial model.AuthResponse
+	if err := json.NewDecoder(login.Body).Decode(&initial); err != nil {
+		t.Fatalf("Ошибка декодирования ответа аутентификации: %v", err)
+	}
+
+	// Act
+	refreshed := performRequest(t, "POST", ts.URL+"/api/auth/refresh", "", model.RefreshRequest{RefreshToken: initial.RefreshToken})
+	var rotated model.AuthResponse
+	if err := json.NewDecoder(refreshed.Body).Decode(&rotated); err != nil {
+		t.Fatalf("Ошибка декодирования ответа обновления: %v", err)
+	}
+	reused := performRequest(t, "POST", ts.URL+"/api/auth/refresh", "", model.RefreshRequest{RefreshToken: initial.RefreshToken})
+	familyRevoked := performRequest(t, "POST", ts.URL+"/api/auth/refresh", "", model.RefreshRequest{RefreshToken: rotated.RefreshToken})
+	logout := performRequest(t, "POST", ts.URL+"/api/logout", rotated.Token, nil)
+	afterLogout := performRequest(t, "GET", ts.URL+"/api/info", rotated.Token, nil)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, refreshed.StatusCode)
+	assert.NotEqual(t, initial.RefreshToken, rotated.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, reused.StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, familyRevoked.StatusCode)
+	assert.Equal(t, http.StatusOK, logout.StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, afterLogout.StatusCode)
+}
+
+func TestJWKS(t *testing.T) {
+	// Arrange
+	clearDB()
+	signingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Ошибка генерации ключа: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(signingKey)
+	if err != nil {
+		t.Fatalf("Ошибка кодирования закрытого ключа: %v", err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "signing.pem")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatalf("Ошибка записи ключа: %v", err)
+	}
+	keySet, err := keys.LoadKeySet(&config.Config{JWTAlgorithm: "ES256", JWTSigningKey: keyPath, JWTSigningKeyID: "es-2026"})
+	if err != nil {
+		t.Fatalf("Ошибка загрузки ключей: %v", err)
+	}
+	router := newTestRouter(db, keySet, metrics.New(), noop.NewTracerProvider())
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	// Act
+	response := performRequest(t, "GET", ts.URL+"/.well-known/jwks.json", "", nil)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	var jwks model.JWKS
+	if err := json.NewDecoder(response.Body).Decode(&jwks); err != nil {
+		t.Fatalf("Ошибка декодирования JWKS: %v", err)
+	}
+	if len(jwks.Keys) != 1 {
+		t.Fatalf("Ожидался 1 ключ в JWKS, получено %d", len(jwks.Keys))
+	}
+	jwk := jwks.Keys[0]
+	assert.Equal(t, "es-2026", jwk.Kid)
+	assert.Equal(t, "EC", jwk.Kty)
+	assert.Equal(t, "ES256", jwk.Alg)
+	assert.Equal(t, "P-256", jwk.Crv)
+
+	// Act
+	token := performAuth(t, ts.URL, "keyholder", "passw0rd")
+	info := performRequest(t, "GET", ts.URL+"/api/info", token, nil)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, info.StatusCode)
+	x, errX := base64.RawURLEncoding.DecodeString(jwk.X)
+	y, errY := base64.RawURLEncoding.DecodeString(jwk.Y)
+	if errX != nil || errY != nil {
+		t.Fatalf("Ошибка декодирования координат ключа: %v, %v", errX, errY)
+	}
+	published := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	parsed, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+		return published, nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, parsed.Valid)
+	assert.Equal(t, "ES256", parsed.Method.Alg())
+	assert.Equal(t, "es-2026", parsed.Header["kid"])
+}
+
+func TestRoleProtectedRoutes(t *testing.T) {
+	// Arrange
+	clearDB()
+	router := setupRouter()
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	adminToken := performAuth(t, ts.URL, "admin", "adminpassw0rd")
+	userToken := performAuth(t, ts.URL, "regular", "passw0rd")
+	protectedRoutes := []struct {
+		method string
+		path   string
+		body   interface{}
+	}{
+		{"POST", "/api/merch/badge", model.MerchRequest{Price: 5}},
+		{"PUT", "/api/merch/badge", model.MerchRequest{Price: 5}},
+		{"DELETE", "/api/merch/badge", nil},
+		{"GET", "/api/admin/users/regular/info", nil},
+		{"POST", "/api/admin/users/regular/credit", model.AdjustmentRequest{Amount: 5, Reason: "bonus"}},
+		{"POST", "/api/admin/users/regular/debit", model.AdjustmentRequest{Amount: 5, Reason: "fine"}},
+	}
+
+	for _, route := range protectedRoutes {
+		// Act
+		response := performRequest(t, route.method, ts.URL+route.path, userToken, route.body)
+
+		// Assert
+		assert.Equal(t, http.StatusForbidden, response.StatusCode, "%s %s", route.method, route.path)
+	}
+
+	// Act
+	response := performRequest(t, "GET", ts.URL+"/api/admin/users/regular/info", adminToken, nil)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	var info model.InfoResponse
+	if err := json.NewDecoder(response.Body).Decode(&info); err != nil {
+		t.Fatalf("Ошибка декодирования ответа: %v", err)
+	}
+	assert.Equal(t, 1000, info.Coins)
+}
+
+func TestCoinAdjustments(t *testing.T) {
+	// Arrange
+	clearDB()
+	router := setupRouter()
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	adminToken := performAuth(t, ts.URL, "admin", "adminpassw0rd")
+	userToken := performAuth(t, ts.URL, "employee", "passw0rd")
+
+	// Act
+	credit := performRequest(t, "POST", ts.URL+"/api/admin/users/employee/credit", adminToken,
+		model.AdjustmentRequest{Amount: 300, Reason: "hackathon prize", Reference: "HR-42"})
+	debit := performRequest(t, "POST", ts.URL+"/api/admin/users/employee/debit", adminToken,
+		model.AdjustmentRequest{Amount: 100, Reason: "duplicate bonus correction"})
+	overdraft := performRequest(t, "POST", ts.URL+"/api/admin/users/employee/debit", adminToken,
+		model.AdjustmentRequest{Amount: 5000, Reason: "correction"})
+	noReason := performRequest(t, "POST", ts.URL+"/api/admin/users/employee/credit", adminToken,
+		model.AdjustmentRequest{Amount: 10})
+	unknownUser := performRequest(t, "POST", ts.URL+"/api/admin/users/ghost/credit", adminToken,
+		model.AdjustmentRequest{Amount: 10, Reason: "bonus"})
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, credit.StatusCode)
+	assert.Equal(t, http.StatusCreated, debit.StatusCode)
+	assert.Equal(t, http.StatusBadRequest, overdraft.StatusCode)
+	assert.Equal(t, http.StatusBadRequest, noReason.StatusCode)
+	assert.Equal(t, http.StatusNotFound, unknownUser.StatusCode)
+
+	response := performRequest(t, "GET", ts.URL+"/api/info", userToken, nil)
+	var info model.InfoResponse
+	if err := json.NewDecoder(response.Body).Decode(&info); err != nil {
+		t.Fatalf("Ошибка декодирования ответа: %v", err)
+	}
+	assert.Equal(t, 1200, info.Coins)
+	assert.Equal(t, []model.AdjustmentHistory{
+		{Type: enum.AdjustmentCredit, Amount: 300, Reason: "hackathon prize", Reference: "HR-42"},
+		{Type: enum.AdjustmentDebit, Amount: 100, Reason: "duplicate bonus correction"},
+	}, info.CoinHistory.Adjustments)
+}
+
+func TestLedgerReconciliation(t *testing.T) {
+	// Arrange
+	clearDB()
+	router := setupRouter()
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	db.Create(&model.Merch{Name: "mug", Price: 40})
+	aliceToken := performAuth(t, ts.URL, "alice", "passw0rd")
+	performAuth(t, ts.URL, "bob", "passw0rd")
+	adminToken := performAuth(t, ts.URL, "admin", "adminpassw0rd")
+	legacy := &model.User{Username: "legacy", Password: "hash", Coins: 300}
+	db.Create(legacy)
+
+	ledgerService := service.NewLedgerService(repository.NewUserRepository(db), repository.NewLedgerRepository(db))
+
+	// Act
+	performRequest(t, "POST", ts.URL+"/api/sendCoin", aliceToken, model.SendCoinRequest{ToUser: "bob", Amount: 150})
+	performRequest(t, "GET", ts.URL+"/api/buy/mug", aliceToken, nil)
+	performRequest(t, "POST", ts.URL+"/api/admin/users/bob/debit", adminToken, model.AdjustmentRequest{Amount: 50, Reason: "correction"})
+	opened, err := ledgerService.OpenBalances(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, opened)
+	mismatches, err := ledgerService.Reconcile(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, mismatches)
+	var total int
+	db.Model(&model.Posting{}).Select("COALESCE(SUM(amount), 0)").Scan(&total)
+	assert.Equal(t, 0, total)
+	var alice model.User
+	db.Where("username = ?", "alice").First(&alice)
+	assert.Equal(t, 810, alice.Coins)
+
+	// Act
+	db.Model(&model.User{}).Where("id = ?", legacy.ID).Update("coins", 999)
+	mismatches, err = ledgerService.Reconcile(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []model.BalanceMismatch{{UserID: legacy.ID, Username: "legacy", CachedCoins: 999, LedgerCoins: 300}}, mismatches)
+}
+
+func TestHistoryPagination(t *testing.T) {
+	// Arrange
+	clearDB()
+	router := setupRouter()
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	db.Create(&model.Merch{Name: "cup", Price: 20})
+	aliceToken := performAuth(t, ts.URL, "alice", "passw0rd")
+	performAuth(t, ts.URL, "bob", "passw0rd")
+	performRequest(t, "POST", ts.URL+"/api/sendCoin", aliceToken, model.SendCoinRequest{ToUser: "bob", Amount: 100})
+	performRequest(t, "POST", ts.URL+"/api/sendCoin", aliceToken, model.SendCoinRequest{ToUser: "bob", Amount: 200})
+	performRequest(t, "GET", ts.URL+"/api/buy/cup", aliceToken, nil)
+
+	readPage := func(url string) model.HistoryPage {
+		response := performRequest(t, "GET", url, aliceToken, nil)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("Ожидался статус 200, получен %d", response.StatusCode)
+		}
+		var page model.HistoryPage
+		if err := json.NewDecoder(response.Body).Decode(&page); err != nil {
+			t.Fatalf("Ошибка декодирования ответа: %v", err)
+		}
+		return page
+	}
+
+	// Act
+	first := readPage(ts.URL + "/api/history?limit=3")
+	second := readPage(ts.URL + "/api/history?limit=3&cursor=" + first.NextCursor)
+	transfers := readPage(ts.URL + "/api/history?type=transfer&direction=out&counterparty=bob")
+
+	// Assert
+	assert.Len(t, first.Items, 3)
+	assert.Equal(t, enum.EntryPurchase, first.Items[0].Type)
+	assert.Equal(t, "cup", first.Items[0].Item)
+	assert.Equal(t, 200, first.Items[1].Amount)
+	assert.Equal(t, "bob", first.Items[1].Counterparty)
+	assert.NotEmpty(t, first.NextCursor)
+	assert.Len(t, second.Items, 1)
+	assert.Equal(t, enum.EntrySignupBonus, second.Items[0].Type)
+	assert.Equal(t, enum.DirectionIn, second.Items[0].Direction)
+	assert.Empty(t, second.NextCursor)
+	assert.Len(t, transfers.Items, 2)
+
+	// Act
+	badCursor := performRequest(t, "GET", ts.URL+"/api/history?cursor=garbage!", aliceToken, nil)
+	infoResponse := performRequest(t, "GET", ts.URL+"/api/info?limit=1", aliceToken, nil)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, badCursor.StatusCode)
+	var info model.InfoResponse
+	if err := json.NewDecoder(infoResponse.Body).Decode(&info); err != nil {
+		t.Fatalf("Ошибка декодирования ответа: %v", err)
+	}
+	assert.Equal(t, []model.SentCoinHistory{{ToUser: "bob", Amount: 200}}, info.CoinHistory.Sent)
+}
+
+func TestRefundPurchase(t *testing.T) {
+	// Arrange
+	clearDB()
+	router := setupRouter()
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	stock := 3
+	db.Create(&model.Merch{Name: "hoody", Price: 300, Stock: &stock})
+	buyerToken := performAuth(t, ts.URL, "buyer", "passw0rd")
+	strangerToken := performAuth(t, ts.URL, "stranger", "passw0rd")
+	adminToken := performAuth(t, ts.URL, "admin", "adminpassw0rd")
+	performRequest(t, "GET", ts.URL+"/api/buy/hoody", buyerToken, nil)
+	performRequest(t, "GET", ts.URL+"/api/buy/hoody", buyerToken, nil)
+	var purchases []model.Purchase
+	db.Order("id").Find(&purchases)
+	if len(purchases) != 2 {
+		t.Fatalf("Ожидалось 2 покупки, получено %d", len(purchases))
+	}
+	refundURL := func(purchase model.Purchase) string {
+		return fmt.Sprintf("%s/api/purchases/%d/refund", ts.URL, purchase.ID)
+	}
+
+	// Act
+	foreign := performRequest(t, "POST", refundURL(purchases[0]), strangerToken, nil)
+	refunded := performRequest(t, "POST", refundURL(purchases[0]), buyerToken, model.RefundRequest{Reason: "wrong size"})
+	repeated := performRequest(t, "POST", refundURL(purchases[0]), buyerToken, nil)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, foreign.StatusCode)
+	assert.Equal(t, http.StatusOK, refunded.StatusCode)
+	assert.Equal(t, http.StatusConflict, repeated.StatusCode)
+	var merch model.Merch
+	db.First(&merch, "name = ?", "hoody")
+	assert.Equal(t, 2, *merch.Stock)
+
+	// Arrange
+	db.Model(&model.Purchase{}).Where("id = ?", purchases[1].ID).Update("created_at", time.Now().Add(-48*time.Hour))
+
+	// Act
+	expired := performRequest(t, "POST", refundURL(purchases[1]), buyerToken, nil)
+	approved := performRequest(t, "POST", refundURL(purchases[1]), adminToken, nil)
+
+	// Assert
+	assert.Equal(t, http.StatusForbidden, expired.StatusCode)
+	assert.Equal(t, http.StatusOK, approved.StatusCode)
+	response := performRequest(t, "GET", ts.URL+"/api/info", buyerToken, nil)
+	var info model.InfoResponse
+	if err := json.NewDecoder(response.Body).Decode(&info); err != nil {
+		t.Fatalf("Ошибка декодирования ответа: %v", err)
+	}
+	assert.Equal(t, 1000, info.Coins)
+	assert.Empty(t, info.Inventory)
+	db.First(&merch, "name = ?", "hoody")
+	assert.Equal(t, 3, *merch.Stock)
+	var audit model.Purchase
+	db.First(&audit, purchases[1].ID)
+	assert.NotNil(t, audit.RefundedAt)
+	assert.NotNil(t, audit.RefundedBy)
+}
+
+// migrationVersion looks up the version of the named migration so tests don't depend on how many come after it
+func migrationVersion(t *testing.T, name string) int {
+	migrations, err := migration.Load()
+	if err != nil {
+		t.Fatalf("Не удалось загрузить миграции: %v", err)
+	}
+	for _, m := range migrations {
+		if m.Name == name {
+			return m.Version
+		}
+	}
+	t.Fatalf("Миграция %s не найдена", name)
+	return 0
+}
+
+func TestPurchasePrices(t *testing.T) {
+	// Arrange
+	clearDB()
+	router := setupRouter()
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	db.Create(&model.Merch{Name: "cap", Price: 50})
+	token := performAuth(t, ts.URL, "shopper", "passw0rd")
+	adminToken := performAuth(t, ts.URL, "admin", "adminpassw0rd")
+	performRequest(t, "POST", ts.URL+"/api/orders", token, model.OrderRequest{Items: []model.OrderLine{{Item: "cap", Quantity: 2}}})
+	performRequest(t, "PUT", ts.URL+"/api/merch/cap", adminToken, model.MerchRequest{Price: 70})
+
+	var shopper model.User
+	db.Where("username = ?", "shopper").First(&shopper)
+	var charged model.Purchase
+	db.Where("user_id = ?", shopper.ID).First(&charged)
+	reverted, err := migrator.DownTo(migrationVersion(t, "purchase_prices") - 1)
+	if err != nil || len(reverted) == 0 || reverted[len(reverted)-1].Name != "purchase_prices" {
+		t.Fatalf("Не удалось откатить миграцию цен покупок: %v", err)
+	}
+	var legacy model.Purchase
+	db.Raw("INSERT INTO purchases (user_id, merch_item, quantity, created_at) VALUES (?, 'cap', 3, ?) RETURNING id", shopper.ID, time.Now()).Scan(&legacy.ID)
+
+	// Act
+	applied, err := migrator.Up()
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Len(t, applied, len(reverted)) {
+		assert.Equal(t, "purchase_prices", applied[0].Name)
+	}
+	db.First(&charged, charged.ID)
+	assert.Equal(t, 50, charged.UnitPrice)
+	assert.Equal(t, 100, charged.Total)
+	assert.False(t, charged.PriceEstimated)
+	db.First(&legacy, legacy.ID)
+	assert.Equal(t, 70, legacy.UnitPrice)
+	assert.Equal(t, 210, legacy.Total)
+	assert.True(t, legacy.PriceEstimated)
+
+	// Act
+	response := performRequest(t, "GET", ts.URL+"/api/history?type=purchase", token, nil)
+
+	// Assert
+	var page model.HistoryPage
+	if err := json.NewDecoder(response.Body).Decode(&page); err != nil {
+		t.Fatalf("Ошибка декодирования ответа: %v", err)
+	}
+	if len(page.Items) != 1 {
+		t.Fatalf("Ожидалась 1 запись истории, получено %d", len(page.Items))
+	}
+	assert.Equal(t, 2, page.Items[0].Quantity)
+	assert.Equal(t, 50, page.Items[0].UnitPrice)
+	assert.Equal(t, 100, page.Items[0].Total)
+}
+
+func TestMigrations(t *testing.T) {
+	// Arrange
+	clearDB()
+	migrations, err := migration.Load()
+	if err != nil {
+		t.Fatalf("Не удалось загрузить миграции: %v", err)
+	}
+	reverted, err := migrator.Down(len(migrations))
+	if err != nil {
+		t.Fatalf("Не удалось откатить миграции: %v", err)
+	}
+	assert.Len(t, reverted, len(migrations))
+
+	// Act
+	var wg sync.WaitGroup
+	applied := make([][]migration.Migration, 2)
+	errs := make([]error, 2)
+	for i := range applied {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			applied[i], errs[i] = migrator.Up()
+		}(i)
+	}
+	wg.Wait()
+
+	// Assert
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	assert.Equal(t, len(migrations), len(applied[0])+len(applied[1]))
+	statuses, err := migrator.Status()
+	assert.NoError(t, err)
+	for _, status := range statuses {
+		assert.NotNil(t, status.AppliedAt, "миграция %04d_%s не применена", status.Version, status.Name)
+	}
+}
+
+func TestIntegrityConstraintsCleanup(t *testing.T) {
+	// Arrange
+	clearDB()
+	db.Exec("TRUNCATE TABLE quarantined_rows")
+	if _, err := migrator.DownTo(migrationVersion(t, "integrity_constraints") - 1); err != nil {
+		t.Fatalf("Не удалось откатить миграцию ограничений: %v", err)
+	}
+	var debtorID, payeeID int
+	db.Raw("INSERT INTO users (username, password, coins) VALUES ('debtor', 'hash', -50) RETURNING id").Scan(&debtorID)
+	db.Raw("INSERT INTO users (username, password, coins) VALUES ('payee', 'hash', 100) RETURNING id").Scan(&payeeID)
+	db.Exec("INSERT INTO merches (name, price) VALUES ('freebie', 0)")
+	db.Exec("INSERT INTO coin_transfers (from_user_id, to_user_id, amount) VALUES (?, ?, 10), (?, ?, 10)", debtorID, debtorID, debtorID, payeeID)
+	db.Exec("INSERT INTO purchases (user_id, merch_item, quantity, unit_price, total) VALUES (?, 'vintage-cap', 1, 40, 40)", payeeID)
+
+	// Act
+	_, err := migrator.Up()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Не удалось применить миграции: %v", err)
+	}
+	var debtor model.User
+	db.First(&debtor, debtorID)
+	assert.Equal(t, 0, debtor.Coins)
+	var freebie model.Merch
+	db.Where("name = ?", "freebie").First(&freebie)
+	assert.Equal(t, 1, freebie.Price)
+	assert.True(t, freebie.Retired)
+	var placeholder model.Merch
+	db.Where("name = ?", "vintage-cap").First(&placeholder)
+	assert.Equal(t, 40, placeholder.Price)
+	assert.True(t, placeholder.Retired)
+	var transfers int64
+	db.Model(&model.CoinTransfer{}).Count(&transfers)
+	assert.Equal(t, int64(1), transfers)
+	var quarantined []string
+	db.Raw("SELECT table_name FROM quarantined_rows ORDER BY id").Scan(&quarantined)
+	assert.Equal(t, []string{"users", "merches", "merches", "coin_transfers"}, quarantined)
+}
+
+func TestIntegrityConstraints(t *testing.T) {
+	// Arrange
+	clearDB()
+	sender := &model.User{Username: "sender", Password: "password", Coins: 100}
+	receiver := &model.User{Username: "receiver", Password: "password", Coins: 100}
+	db.Create(sender)
+	db.Create(receiver)
+	db.Create(&model.Merch{Name: "hoody", Price: 300})
+	userRepo := repository.NewUserRepository(db)
+	transferRepo := repository.NewCoinTransferRepository(db)
+	purchaseRepo := repository.NewPurchaseRepository(db)
+
+	// Act
+	negativeErr := userRepo.UpdateCoins(context.Background(), sender.ID, -1)
+	zeroErr := transferRepo.Create(context.Background(), &model.CoinTransfer{FromUserID: sender.ID, ToUserID: receiver.ID, Amount: 0, CreatedAt: time.Now()})
+	selfErr := transferRepo.Create(context.Background(), &model.CoinTransfer{FromUserID: sender.ID, ToUserID: sender.ID, Amount: 10, CreatedAt: time.Now()})
+	ghostErr := transferRepo.Create(context.Background(), &model.CoinTransfer{FromUserID: sender.ID, ToUserID: 999, Amount: 10, CreatedAt: time.Now()})
+	unknownItemErr := purchaseRepo.Create(context.Background(), &model.Purchase{UserID: sender.ID, MerchItem: "candy", Quantity: 1, CreatedAt: time.Now()})
+	duplicateErr := userRepo.Create(context.Background(), &model.User{Username: "sender", Password: "password"})
+
+	// Assert
+	assert.Equal(t, enum.ErrInsufficientMoney, negativeErr)
+	assert.Equal(t, enum.ErrCoinsInappropriateAmount, zeroErr)
+	assert.Equal(t, enum.ErrEqualReceivers, selfErr)
+	assert.Equal(t, enum.ErrReceiverNotFound, ghostErr)
+	assert.Equal(t, enum.ErrItemNotFound, unknownItemErr)
+	assert.Equal(t, enum.ErrUserAlreadyExists, duplicateErr)
+	var transfers, purchases int64
+	db.Model(&model.CoinTransfer{}).Count(&transfers)
+	db.Model(&model.Purchase{}).Count(&purchases)
+	assert.Equal(t, int64(0), transfers)
+	assert.Equal(t, int64(0), purchases)
+}
+
+func TestErrorMiddleware(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorMiddleware())
+	router.GET("/domain", func(c *gin.Context) {
+		c.Error(enum.ErrItemNotFound)
+	})
+	router.GET("/wrapped", func(c *gin.Context) {
+		c.Error(enum.ErrReceivingHistory.Wrap(errors.New("pq: relation \"postings\" does not exist")))
+	})
+	router.GET("/internal", func(c *gin.Context) {
+		c.Error(errors.New("dial tcp 10.0.0.5:5432: connection refused"))
+	})
+
+	for _, tc := range []struct {
+		path   string
+		status int
+		code   string
+	}{
+		{"/domain", http.StatusNotFound, "item_not_found"},
+		{"/wrapped", http.StatusInternalServerError, "receiving_history"},
+		{"/internal", http.StatusInternalServerError, "internal_server_error"},
+	} {
+		// Act
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", tc.path, nil))
+
+		// Assert
+		var problem model.Problem
+		if err := json.NewDecoder(recorder.Body).Decode(&problem); err != nil {
+			t.Fatalf("Ошибка декодирования ответа %s: %v", tc.path, err)
+		}
+		assert.Equal(t, tc.status, recorder.Code, tc.path)
+		assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"), tc.path)
+		assert.Equal(t, tc.status, problem.Status, tc.path)
+		assert.Equal(t, tc.code, problem.Code, tc.path)
+		assert.NotContains(t, problem.Detail, "pq:", tc.path)
+		assert.NotContains(t, problem.Detail, "5432", tc.path)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorMiddleware())
+	router.Use(TimeoutMiddleware(100 * time.Millisecond))
+	router.GET("/slow", func(c *gin.Context) {
+		if err := db.WithContext(c.Request.Context()).Exec("SELECT pg_sleep(5)").Error; err != nil {
+			c.Error(enum.ErrInternalServer.Wrap(err))
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	// Act
+	start := time.Now()
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/slow", nil))
+	elapsed := time.Since(start)
+
+	// Assert
+	var problem model.Problem
+	if err := json.NewDecoder(recorder.Body).Decode(&problem); err != nil {
+		t.Fatalf("Ошибка декодирования ответа: %v", err)
+	}
+	assert.Equal(t, http.StatusGatewayTimeout, recorder.Code)
+	assert.Equal(t, "request_timeout", problem.Code)
+	assert.Equal(t, http.StatusGatewayTimeout, problem.Status)
+	assert.Less(t, elapsed, 2*time.Second)
+}
+
+func TestRequestLogging(t *testing.T) {
+	// Arrange
+	clearDB()
+	router := setupRouter()
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, "info", "json")
+	if err != nil {
+		t.Fatalf("Ошибка создания логгера: %v", err)
+	}
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	defer slog.SetDefault(previous)
+
+	serve := func(method, path, token, requestID string, body interface{}) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(body)
+		request := httptest.NewRequest(method, path, bytes.NewBuffer(payload))
+		request.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		if requestID != "" {
+			request.Header.Set("X-Request-ID", requestID)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// Act
+	login := serve("POST", "/api/auth", "", "login-request-1", model.AuthRequest{Username: "logger", Password: "very_secret_passw0rd"})
+	var session model.AuthResponse
+	if err := json.NewDecoder(login.Body).Decode(&session); err != nil {
+		t.Fatalf("Ошибка декодирования ответа аутентификации: %v", err)
+	}
+	buy := serve("GET", "/api/buy/unknown", session.Token, "", nil)
+
+	// Assert
+	assert.Equal(t, "login-request-1", login.Header().Get("X-Request-ID"))
+	generatedID := buy.Header().Get("X-Request-ID")
+	assert.Len(t, generatedID, 32)
+
+	var records []map[string]interface{}
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var record map[string]interface{}
+		if err := json.Unmarshal(line, &record); err != nil {
+			t.Fatalf("Строка лога не является JSON: %s", line)
+		}
+		records = append(records, record)
+	}
+	find := func(requestID, msg string) map[string]interface{} {
+		for _, record := range records {
+			if record["request_id"] == requestID && record["msg"] == msg {
+				return record
+			}
+		}
+		t.Fatalf("В логе нет записи %q для запроса %s", msg, requestID)
+		return nil
+	}
+	loginRecord := find("login-request-1", "request completed")
+	assert.Equal(t, "/api/auth", loginRecord["route"])
+	assert.Equal(t, float64(http.StatusOK), loginRecord["status"])
+	rejected := find(generatedID, "request rejected")
+	assert.Equal(t, "/api/buy/:item", rejected["route"])
+	assert.Equal(t, "item_not_found", rejected["code"])
+	assert.NotNil(t, rejected["user_id"])
+	assert.NotContains(t, buf.String(), "very_secret_passw0rd")
+	assert.NotContains(t, buf.String(), session.Token)
+}
+
+func TestMetrics(t *testing.T) {
+	// Arrange
+	clearDB()
+	appMetrics := metrics.New()
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("Ошибка получения соединения с базой данных: %v", err)
+	}
+	if err := appMetrics.RegisterDB(sqlDB); err != nil {
+		t.Fatalf("Ошибка регистрации метрик базы данных: %v", err)
+	}
+	router := newTestRouter(db, keys.NewHMACKeySet([]byte("elaborate_secret")), appMetrics, noop.NewTracerProvider())
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	db.Create(&model.Merch{Name: "cup", Price: 20})
+	login := performRequest(t, "POST", ts.URL+"/api/auth", "", model.AuthRequest{Username: "customer", Password: "passw0rd"})
+	var session model.AuthResponse
+	if err := json.NewDecoder(login.Body).Decode(&session); err != nil {
+		t.Fatalf("Ошибка декодирования ответа аутентификации: %v", err)
+	}
+	performRequest(t, "POST", ts.URL+"/api/auth", "", model.AuthRequest{Username: "receiver", Password: "passw0rd"})
+
+	// Act
+	performRequest(t, "GET", ts.URL+"/api/buy/cup", session.Token, nil)
+	performRequest(t, "GET", ts.URL+"/api/buy/unknown", session.Token, nil)
+	performRequest(t, "POST", ts.URL+"/api/sendCoin", session.Token, model.SendCoinRequest{ToUser: "receiver", Amount: 30})
+	response := performRequest(t, "GET", ts.URL+"/metrics", "", nil)
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("Ошибка чтения метрик: %v", err)
+	}
+
+	// Assert
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	exposition := string(body)
+	assert.Contains(t, exposition, `merch_store_purchases_total{item="cup"} 1`)
+	assert.Contains(t, exposition, "merch_store_coins_spent_total 20")
+	assert.Contains(t, exposition, `merch_store_purchase_failures_total{code="item_not_found"} 1`)
+	assert.Contains(t, exposition, "merch_store_coin_transfers_total 1")
+	assert.Contains(t, exposition, "merch_store_coin_transfer_volume_total 30")
+	assert.Contains(t, exposition, `merch_store_user_registrations_total{source="authenticate"} 2`)
+	assert.Contains(t, exposition, `merch_store_http_request_duration_seconds_count{method="GET",route="/api/buy/:item",status="200"} 1`)
+	assert.Contains(t, exposition, `merch_store_http_request_duration_seconds_count{method="GET",route="/api/buy/:item",status="404"} 1`)
+	assert.Contains(t, exposition, `go_sql_open_connections{db_name="merch_store"}`)
+}
+
+func TestTracing(t *testing.T) {
+	// Arrange
+	clearDB()
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("Ошибка получения соединения с базой данных: %v", err)
+	}
+	tracedDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Ошибка подключения к базе данных: %v", err)
+	}
+	if err := tracing.InstrumentDB(tracedDB, tracerProvider); err != nil {
+		t.Fatalf("Ошибка подключения трассировки запросов: %v", err)
+	}
+	router := newTestRouter(tracedDB, keys.NewHMACKeySet([]byte("elaborate_secret")), metrics.New(), tracerProvider)
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	db.Create(&model.Merch{Name: "cup", Price: 20})
+	login := performRequest(t, "POST", ts.URL+"/api/auth", "", model.AuthRequest{Username: "traced", Password: "passw0rd"})
+	var session model.AuthResponse
+	if err := json.NewDecoder(login.Body).Decode(&session); err != nil {
+		t.Fatalf("Ошибка декодирования ответа аутентификации: %v", err)
+	}
+
+	// Act
+	request, err := http.NewRequest("GET", ts.URL+"/api/buy/cup", nil)
+	if err != nil {
+		t.Fatalf("Ошибка создания запроса: %v", err)
+	}
+	request.Header.Set("Authorization", "Bearer "+session.Token)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("Ошибка выполнения запроса покупки: %v", err)
+	}
+	response.Body.Close()
+
+	// Assert
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	var serverSpan, serviceSpan sdktrace.ReadOnlySpan
+	var querySpans []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			continue
+		}
+		switch {
+		case span.Name() == "/api/buy/:item":
+			serverSpan = span
+		case span.Name() == "MerchService.BuyMerch":
+			serviceSpan = span
+		case strings.HasPrefix(span.Name(), "gorm."):
+			querySpans = append(querySpans, span)
+		}
+	}
+	if serverSpan == nil || serviceSpan == nil {
+		t.Fatalf("В трассировке нет span запроса или сервиса")
+	}
+	assert.Equal(t, "00f067aa0ba902b7", serverSpan.Parent().SpanID().String())
+	assert.True(t, serverSpan.Parent().IsRemote())
+	assert.Equal(t, serverSpan.SpanContext().SpanID(), serviceSpan.Parent().SpanID())
+	lockedRowRead := false
+	for _, span := range querySpans {
+		if span.Parent().SpanID() != serviceSpan.SpanContext().SpanID() {
+			continue
+		}
+		for _, attr := range span.Attributes() {
+			if attr.Key == "db.statement" && strings.Contains(attr.Value.AsString(), "FOR UPDATE") {
+				lockedRowRead = true
+			}
+		}
+	}
+	assert.True(t, lockedRowRead, "в трассировке покупки нет запроса с блокировкой строки пользователя")
+}
+
+func TestLocalizedMessages(t *testing.T) {
+	// Arrange
+	clearDB()
+	router := setupRouter()
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	db.Create(&model.Merch{Name: "cup", Price: 20})
+	login := performRequest(t, "POST", ts.URL+"/api/auth", "", model.AuthRequest{Username: "traveller", Password: "passw0rd"})
+	var session model.AuthResponse
+	if err := json.NewDecoder(login.Body).Decode(&session); err != nil {
+		t.Fatalf("Ошибка декодирования ответа аутентификации: %v", err)
+	}
+	buy := func(token, item, acceptLanguage string) (*http.Response, map[string]interface{}) {
+		request, err := http.NewRequest("GET", ts.URL+"/api/buy/"+item, nil)
+		if err != nil {
+			t.Fatalf("Ошибка создания запроса: %v", err)
+		}
+		request.Header.Set("Authorization", "Bearer "+token)
+		request.Header.Set("Accept-Language", acceptLanguage)
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("Ошибка выполнения запроса покупки: %v", err)
+		}
+		defer response.Body.Close()
+		var body map[string]interface{}
+		if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+			t.Fatalf("Ошибка декодирования ответа: %v", err)
+		}
+		return response, body
+	}
+
+	// Act
+	notFoundEn, notFoundEnBody := buy(session.Token, "candy", "en-GB,en;q=0.9")
+	_, notFoundRuBody := buy(session.Token, "candy", "")
+	unsupported := performRequest(t, "PUT", ts.URL+"/api/locale", session.Token, model.LocaleRequest{Locale: "de"})
+	saved := performRequest(t, "PUT", ts.URL+"/api/locale", session.Token, model.LocaleRequest{Locale: enum.LocaleEn})
+	sameToken, sameTokenBody := buy(session.Token, "candy", "ru")
+	refreshed := performRequest(t, "POST", ts.URL+"/api/auth/refresh", "", model.RefreshRequest{RefreshToken: session.RefreshToken})
+	var rotated model.AuthResponse
+	if err := json.NewDecoder(refreshed.Body).Decode(&rotated); err != nil {
+		t.Fatalf("Ошибка декодирования ответа обновления: %v", err)
+	}
+	_, boughtBody := buy(rotated.Token, "cup", "ru")
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, notFoundEn.StatusCode)
+	assert.Equal(t, "en", notFoundEn.Header.Get("Content-Language"))
+	assert.Equal(t, "item_not_found", notFoundEnBody["code"])
+	assert.Equal(t, "item not found", notFoundEnBody["detail"])
+	assert.Equal(t, "item_not_found", notFoundRuBody["code"])
+	assert.Equal(t, "товар не найден", notFoundRuBody["detail"])
+	assert.Equal(t, http.StatusBadRequest, unsupported.StatusCode)
+	assert.Equal(t, http.StatusOK, saved.StatusCode)
+	assert.Equal(t, "en", sameToken.Header.Get("Content-Language"))
+	assert.Equal(t, "item not found", sameTokenBody["detail"])
+	assert.Equal(t, enum.SuccessfulPurchase.String(), boughtBody["code"])
+	assert.Equal(t, "purchase completed successfully", boughtBody["message"])
+}
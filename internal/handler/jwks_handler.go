@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ners1us/merch_store/internal/keys"
+	"net/http"
+)
+
+type JWKSHandler struct {
+	keySet *keys.KeySet
+}
+
+func NewJWKSHandler(keySet *keys.KeySet) *JWKSHandler {
+	return &JWKSHandler{keySet: keySet}
+}
+
+func (jh *JWKSHandler) HandleJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jh.keySet.JWKS())
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/i18n"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/service"
+	"net/http"
+)
+
+// LocaleMiddleware lets requestLocale read the saved preference of the authenticated user from the database,
+// so a language set with PUT /api/locale applies from the next request on, without waiting for a new token
+func LocaleMiddleware(userService service.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("user_service", userService)
+		c.Next()
+	}
+}
+
+// requestLocale picks the language of response texts: the user's saved preference, then Accept-Language.
+// The preference is looked up once per request, the one in the token is used if the lookup fails
+func requestLocale(c *gin.Context) enum.Locale {
+	if locale, exists := c.Get("locale"); exists {
+		return locale.(enum.Locale)
+	}
+
+	var preferred enum.Locale
+	if value, exists := c.Get("claims"); exists {
+		claims := value.(*model.Claims)
+		preferred = claims.Locale
+		if userService, exists := c.Get("user_service"); exists {
+			if saved, err := userService.(service.UserService).GetLocale(c.Request.Context(), claims.UserID); err == nil {
+				preferred = saved
+			}
+		}
+	}
+	locale := i18n.Negotiate(preferred, c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", locale.String())
+	c.Set("locale", locale)
+	return locale
+}
+
+func respondMessage(c *gin.Context, messageType enum.MessageType) {
+	c.JSON(http.StatusOK, gin.H{"code": messageType.String(), "message": i18n.Message(requestLocale(c), messageType)})
+}
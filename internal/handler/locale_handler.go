@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/i18n"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/service"
+	"net/http"
+	"strconv"
+)
+
+type LocaleHandler struct {
+	userService service.UserService
+}
+
+func NewLocaleHandler(userService service.UserService) *LocaleHandler {
+	return &LocaleHandler{userService: userService}
+}
+
+// HandleSetLocale saves the preferred language, it applies to the following requests made with any token
+func (lh *LocaleHandler) HandleSetLocale(c *gin.Context) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.Error(enum.ErrUserNotAuthorized)
+		return
+	}
+	userID, err := strconv.Atoi(userIDStr.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req model.LocaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(enum.ErrWrongReqFormat)
+		return
+	}
+
+	if err := lh.userService.SetLocale(c.Request.Context(), userID, req.Locale); err != nil {
+		c.Error(err)
+		return
+	}
+
+	// The confirmation is already in the newly chosen language
+	locale := i18n.Negotiate(req.Locale, c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", locale.String())
+	c.JSON(http.StatusOK, gin.H{
+		"code":    enum.SuccessfulLocaleChange.String(),
+		"message": i18n.Message(locale, enum.SuccessfulLocaleChange),
+		"locale":  req.Locale,
+	})
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ners1us/merch_store/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"strconv"
+	"time"
+)
+
+// MetricsMiddleware observes the duration of every request by method, route template and status code
+func MetricsMiddleware(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.HTTPRequestDuration.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
+
+// MetricsHandler serves the collected metrics in the Prometheus exposition format
+func MetricsHandler(m *metrics.Metrics) gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{}))
+}
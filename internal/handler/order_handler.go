@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/service"
+	"net/http"
+	"strconv"
+)
+
+type OrderHandler struct {
+	orderService service.OrderService
+}
+
+func NewOrderHandler(orderService service.OrderService) *OrderHandler {
+	return &OrderHandler{orderService: orderService}
+}
+
+func (oh *OrderHandler) HandlePlaceOrder(c *gin.Context) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.Error(enum.ErrUserNotAuthorized)
+		return
+	}
+	userID, err := strconv.Atoi(userIDStr.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req model.OrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(enum.ErrWrongReqFormat)
+		return
+	}
+
+	order, err := oh.orderService.PlaceOrder(c.Request.Context(), userID, req.Items)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, order)
+}
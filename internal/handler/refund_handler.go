@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/service"
+	"net/http"
+	"strconv"
+)
+
+type RefundHandler struct {
+	refundService service.RefundService
+}
+
+func NewRefundHandler(refundService service.RefundService) *RefundHandler {
+	return &RefundHandler{refundService: refundService}
+}
+
+func (rh *RefundHandler) HandleRefund(c *gin.Context) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.Error(enum.ErrUserNotAuthorized)
+		return
+	}
+	userID, _ := strconv.Atoi(userIDStr.(string))
+	role, _ := c.Get("role")
+	userRole, _ := role.(enum.Role)
+
+	purchaseID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(enum.ErrPurchaseNotFound)
+		return
+	}
+
+	// The reason is optional, so an empty body is accepted
+	var req model.RefundRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(enum.ErrWrongReqFormat)
+			return
+		}
+	}
+
+	purchase, err := rh.refundService.RefundPurchase(c.Request.Context(), userID, userRole, purchaseID, req.Reason)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, purchase)
+}
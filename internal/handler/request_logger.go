@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/ners1us/merch_store/internal/logging"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+const (
+	requestIDHeader    = "X-Request-ID"
+	maxRequestIDLength = 128
+)
+
+// RequestLogger tags the request context with a request ID, taken from X-Request-ID or generated and echoed back,
+// the method, the route and the trace ID when the request is traced, and logs every completed request
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		requestID := c.GetHeader(requestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		c.Set("request_id", requestID)
+		c.Header(requestIDHeader, requestID)
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx := logging.With(c.Request.Context(), "request_id", requestID, "method", c.Request.Method, "route", route)
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+			ctx = logging.With(ctx, "trace_id", spanContext.TraceID().String())
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		level := slog.LevelInfo
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		slog.Log(c.Request.Context(), level, "request completed",
+			"status", c.Writer.Status(),
+			"duration_ms", time.Since(start).Milliseconds(),
+			"response_size", c.Writer.Size(),
+			"client_ip", c.ClientIP(),
+		)
+	}
+}
+
+// RecoveryMiddleware logs a panic of a handler and reports it as an internal error
+func RecoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered any) {
+		slog.ErrorContext(c.Request.Context(), "panic recovered", "panic", recovered, "stack", string(debug.Stack()))
+		c.Error(fmt.Errorf("panic: %v", recovered))
+		c.Abort()
+	})
+}
+
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
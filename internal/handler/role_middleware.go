@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ners1us/merch_store/internal/enum"
+	"slices"
+)
+
+func RequireRole(roles ...enum.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get("role")
+		if userRole, ok := role.(enum.Role); !ok || !slices.Contains(roles, userRole) {
+			c.Error(enum.ErrForbidden)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"github.com/ners1us/merch_store/internal/enum"
 	"github.com/ners1us/merch_store/internal/model"
 	"github.com/ners1us/merch_store/internal/service"
-	"net/http"
 	"strconv"
 )
 
@@ -20,22 +19,22 @@ func NewSendCoinHandler(transferService service.TransferService) *SendCoinHandle
 func (sch *SendCoinHandler) HandleSendCoin(c *gin.Context) {
 	userIDStr, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": enum.ErrUserNotAuthorized.Error()})
+		c.Error(enum.ErrUserNotAuthorized)
 		return
 	}
 	userID, _ := strconv.Atoi(userIDStr.(string))
 
 	var req model.SendCoinRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": enum.ErrWrongReqFormat.Error()})
+		c.Error(enum.ErrWrongReqFormat)
 		return
 	}
 
-	err := sch.transferService.SendCoin(userID, req.ToUser, req.Amount)
+	err := sch.transferService.SendCoin(c.Request.Context(), userID, req.ToUser, req.Amount)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": enum.SuccessfulTransfer.String()})
+	respondMessage(c, enum.SuccessfulTransfer)
 }
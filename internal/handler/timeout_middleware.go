@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+// TimeoutMiddleware bounds the whole request, its queries share one deadline and are cancelled once the timeout passes
+// or the client disconnects. A zero timeout leaves the context unbounded
+func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
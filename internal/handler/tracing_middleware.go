@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ners1us/merch_store/internal/tracing"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware starts a server span for every request, continuing the trace of the W3C traceparent header.
+// Metrics scrapes are not traced
+func TracingMiddleware(provider trace.TracerProvider) gin.HandlerFunc {
+	return otelgin.Middleware(tracing.ServiceName,
+		otelgin.WithTracerProvider(provider),
+		otelgin.WithPropagators(tracing.Propagator()),
+		otelgin.WithGinFilter(func(c *gin.Context) bool {
+			return c.FullPath() != "/metrics"
+		}),
+	)
+}
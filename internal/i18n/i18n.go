@@ -0,0 +1,95 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"github.com/ners1us/merch_store/internal/enum"
+	"golang.org/x/text/language"
+)
+
+//go:embed locales/*.json
+var files embed.FS
+
+// DefaultLocale is used when neither the user nor the request asks for a supported language
+const DefaultLocale = enum.LocaleRu
+
+type catalog struct {
+	Errors   map[string]string `json:"errors"`
+	Messages map[string]string `json:"messages"`
+}
+
+var (
+	locales  = []enum.Locale{enum.LocaleRu, enum.LocaleEn}
+	catalogs = loadCatalogs()
+	matcher  = newMatcher()
+)
+
+func loadCatalogs() map[enum.Locale]catalog {
+	catalogs := make(map[enum.Locale]catalog, len(locales))
+	for _, locale := range locales {
+		data, err := files.ReadFile("locales/" + locale.String() + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("missing message catalog for %s: %v", locale, err))
+		}
+		var c catalog
+		if err := json.Unmarshal(data, &c); err != nil {
+			panic(fmt.Sprintf("invalid message catalog for %s: %v", locale, err))
+		}
+		catalogs[locale] = c
+	}
+	return catalogs
+}
+
+func newMatcher() language.Matcher {
+	tags := make([]language.Tag, len(locales))
+	for i, locale := range locales {
+		tags[i] = language.Make(locale.String())
+	}
+	return language.NewMatcher(tags)
+}
+
+// Locales lists the shipped locales, the default one first
+func Locales() []enum.Locale {
+	return append([]enum.Locale(nil), locales...)
+}
+
+func Supported(locale enum.Locale) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// Negotiate prefers the locale chosen by the user and falls back to the Accept-Language header
+func Negotiate(preferred enum.Locale, acceptLanguage string) enum.Locale {
+	if Supported(preferred) {
+		return preferred
+	}
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLocale
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLocale
+	}
+	return locales[index]
+}
+
+// Error returns the text of the error in the locale, the default locale and then the code itself are fallbacks
+func Error(locale enum.Locale, errorType enum.ErrorType) string {
+	return lookup(locale, errorType.Code(), func(c catalog) map[string]string { return c.Errors })
+}
+
+func Message(locale enum.Locale, messageType enum.MessageType) string {
+	return lookup(locale, messageType.String(), func(c catalog) map[string]string { return c.Messages })
+}
+
+func lookup(locale enum.Locale, code string, section func(catalog) map[string]string) string {
+	if text, ok := section(catalogs[locale])[code]; ok {
+		return text
+	}
+	if text, ok := section(catalogs[DefaultLocale])[code]; ok {
+		return text
+	}
+	return code
+}
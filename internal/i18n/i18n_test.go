@@ -0,0 +1,65 @@
+package i18n
+
+import (
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCatalogsTranslateEveryErrorType(t *testing.T) {
+	for _, locale := range Locales() {
+		for _, errorType := range enum.ErrorTypes() {
+			// Act
+			_, ok := catalogs[locale].Errors[errorType.Code()]
+
+			// Assert
+			assert.True(t, ok, "%s has no translation for error %s", locale, errorType.Code())
+		}
+	}
+}
+
+func TestCatalogsTranslateEveryMessageType(t *testing.T) {
+	for _, locale := range Locales() {
+		for _, messageType := range enum.MessageTypes() {
+			// Act
+			_, ok := catalogs[locale].Messages[messageType.String()]
+
+			// Assert
+			assert.True(t, ok, "%s has no translation for message %s", locale, messageType)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	for _, tc := range []struct {
+		preferred      enum.Locale
+		acceptLanguage string
+		expected       enum.Locale
+	}{
+		{"", "", enum.LocaleRu},
+		{"", "en-US,en;q=0.9", enum.LocaleEn},
+		{"", "de-DE,en;q=0.5,ru;q=0.8", enum.LocaleRu},
+		{"", "fr", enum.LocaleRu},
+		{"", "not a header", enum.LocaleRu},
+		{enum.LocaleEn, "ru", enum.LocaleEn},
+		{"de", "en", enum.LocaleEn},
+	} {
+		// Act
+		locale := Negotiate(tc.preferred, tc.acceptLanguage)
+
+		// Assert
+		assert.Equal(t, tc.expected, locale, "preferred %q, Accept-Language %q", tc.preferred, tc.acceptLanguage)
+	}
+}
+
+func TestError(t *testing.T) {
+	// Act
+	ru := Error(enum.LocaleRu, enum.ErrItemNotFound)
+	en := Error(enum.LocaleEn, enum.ErrItemNotFound)
+	unknown := Error("de", enum.ErrItemNotFound)
+
+	// Assert
+	assert.Equal(t, "товар не найден", ru)
+	assert.Equal(t, "item not found", en)
+	assert.Equal(t, ru, unknown)
+}
@@ -0,0 +1,259 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt"
+	"github.com/ners1us/merch_store/internal/config"
+	"github.com/ners1us/merch_store/internal/model"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrUnknownKeyID       = errors.New("unknown key id")
+	ErrUnexpectedAlg      = errors.New("unexpected signing algorithm")
+	ErrUnsupportedKeyType = errors.New("unsupported key type")
+)
+
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+func NewHMACKeySet(secret []byte) *KeySet {
+	key := &Key{ID: "hs256", Method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}
+	return &KeySet{signing: key, keys: map[string]*Key{key.ID: key}}
+}
+
+func LoadKeySet(cfg *config.Config) (*KeySet, error) {
+	if cfg.JWTAlgorithm == jwt.SigningMethodHS256.Alg() {
+		if cfg.JWTSecret == "" {
+			return nil, errors.New("JWT_SECRET is required for HS256")
+		}
+		keySet := NewHMACKeySet([]byte(cfg.JWTSecret))
+		if cfg.JWTSigningKeyID != "" {
+			keySet.signing.ID = cfg.JWTSigningKeyID
+			keySet.keys = map[string]*Key{cfg.JWTSigningKeyID: keySet.signing}
+		}
+		return keySet, nil
+	}
+
+	if cfg.JWTSigningKey == "" {
+		return nil, fmt.Errorf("JWT_SIGNING_KEY_FILE is required for %s", cfg.JWTAlgorithm)
+	}
+	signing, err := loadKey(cfg.JWTSigningKey, cfg.JWTSigningKeyID)
+	if err != nil {
+		return nil, err
+	}
+	if signing.signKey == nil {
+		return nil, fmt.Errorf("%s does not contain a private key", cfg.JWTSigningKey)
+	}
+	if signing.Method.Alg() != cfg.JWTAlgorithm {
+		return nil, fmt.Errorf("%s holds a %s key, but JWT_ALGORITHM is %s", cfg.JWTSigningKey, signing.Method.Alg(), cfg.JWTAlgorithm)
+	}
+
+	keySet := &KeySet{signing: signing, keys: map[string]*Key{signing.ID: signing}}
+	for _, entry := range cfg.JWTVerifyingKeys {
+		kid, path := "", entry
+		if i := strings.Index(entry, "="); i >= 0 {
+			kid, path = entry[:i], entry[i+1:]
+		}
+		key, err := loadKey(path, kid)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := keySet.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		keySet.keys[key.ID] = key
+	}
+	return keySet, nil
+}
+
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.Method, claims)
+	token.Header["kid"] = ks.signing.ID
+	return token.SignedString(ks.signing.signKey)
+}
+
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	key := ks.signing
+	if kid, ok := token.Header["kid"].(string); ok {
+		key, ok = ks.keys[kid]
+		if !ok {
+			return nil, ErrUnknownKeyID
+		}
+	} else if key.Method != jwt.SigningMethodHS256 {
+		return nil, ErrUnknownKeyID
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrUnexpectedAlg
+	}
+	return key.verifyKey, nil
+}
+
+func (ks *KeySet) JWKS() model.JWKS {
+	jwks := model.JWKS{Keys: []model.JWK{}}
+	for _, key := range ks.keys {
+		if jwk, err := publicJWK(key.verifyKey); err == nil {
+			jwk.Kid = key.ID
+			jwk.Alg = key.Method.Alg()
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].Kid < jwks.Keys[j].Kid })
+	return jwks
+}
+
+func loadKey(path, kid string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM file", path)
+	}
+
+	var private crypto.Signer
+	var public crypto.PublicKey
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", path, ErrUnsupportedKeyType)
+		}
+		private = signer
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		private, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		public, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		public, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: unsupported PEM block %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if private != nil {
+		public = private.Public()
+	}
+
+	method, err := methodForKey(public)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if kid == "" {
+		kid, err = thumbprint(public)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	key := &Key{ID: kid, Method: method, verifyKey: public}
+	if private != nil {
+		key.signKey = private
+	}
+	return key, nil
+}
+
+func methodForKey(public crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch public := public.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PublicKey:
+		switch public.Curve {
+		case elliptic.P256():
+			return jwt.SigningMethodES256, nil
+		case elliptic.P384():
+			return jwt.SigningMethodES384, nil
+		case elliptic.P521():
+			return jwt.SigningMethodES512, nil
+		}
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	}
+	return nil, ErrUnsupportedKeyType
+}
+
+func publicJWK(public interface{}) (model.JWK, error) {
+	encode := base64.RawURLEncoding.EncodeToString
+	switch public := public.(type) {
+	case *rsa.PublicKey:
+		return model.JWK{
+			Kty: "RSA",
+			Use: "sig",
+			N:   encode(public.N.Bytes()),
+			E:   encode(big.NewInt(int64(public.E)).Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		ecdhKey, err := public.ECDH()
+		if err != nil {
+			return model.JWK{}, err
+		}
+		point := ecdhKey.Bytes()[1:]
+		size := len(point) / 2
+		return model.JWK{
+			Kty: "EC",
+			Use: "sig",
+			Crv: public.Curve.Params().Name,
+			X:   encode(point[:size]),
+			Y:   encode(point[size:]),
+		}, nil
+	case ed25519.PublicKey:
+		return model.JWK{Kty: "OKP", Use: "sig", Crv: "Ed25519", X: encode(public)}, nil
+	}
+	return model.JWK{}, ErrUnsupportedKeyType
+}
+
+// thumbprint derives a key ID as described in RFC 7638
+func thumbprint(public crypto.PublicKey) (string, error) {
+	jwk, err := publicJWK(public)
+	if err != nil {
+		return "", err
+	}
+
+	var members map[string]string
+	switch jwk.Kty {
+	case "RSA":
+		members = map[string]string{"e": jwk.E, "kty": jwk.Kty, "n": jwk.N}
+	case "EC":
+		members = map[string]string{"crv": jwk.Crv, "kty": jwk.Kty, "x": jwk.X, "y": jwk.Y}
+	default:
+		members = map[string]string{"crv": jwk.Crv, "kty": jwk.Kty, "x": jwk.X}
+	}
+	canonical, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,152 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt"
+	"github.com/ners1us/merch_store/internal/config"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeKey(t *testing.T, name string, key crypto.Signer, private bool) string {
+	var block *pem.Block
+	if private {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatalf("Ошибка кодирования закрытого ключа: %v", err)
+		}
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	} else {
+		der, err := x509.MarshalPKIXPublicKey(key.Public())
+		if err != nil {
+			t.Fatalf("Ошибка кодирования открытого ключа: %v", err)
+		}
+		block = &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("Ошибка записи ключа: %v", err)
+	}
+	return path
+}
+
+func TestLoadKeySet_Algorithms(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	for _, tc := range []struct {
+		algorithm string
+		key       crypto.Signer
+		kty       string
+	}{
+		{"RS256", rsaKey, "RSA"},
+		{"ES256", ecKey, "EC"},
+		{"EdDSA", edKey, "OKP"},
+	} {
+		t.Run(tc.algorithm, func(t *testing.T) {
+			// Arrange
+			keySet, err := LoadKeySet(&config.Config{
+				JWTAlgorithm:  tc.algorithm,
+				JWTSigningKey: writeKey(t, "signing.pem", tc.key, true),
+			})
+			assert.NoError(t, err)
+
+			// Act
+			tokenStr, err := keySet.Sign(&jwt.StandardClaims{Subject: "alice"})
+			assert.NoError(t, err)
+			claims := &jwt.StandardClaims{}
+			token, err := jwt.ParseWithClaims(tokenStr, claims, keySet.Keyfunc)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.True(t, token.Valid)
+			assert.Equal(t, tc.algorithm, token.Method.Alg())
+			assert.Equal(t, "alice", claims.Subject)
+			jwks := keySet.JWKS()
+			assert.Len(t, jwks.Keys, 1)
+			assert.Equal(t, tc.kty, jwks.Keys[0].Kty)
+			assert.Equal(t, token.Header["kid"], jwks.Keys[0].Kid)
+		})
+	}
+}
+
+func TestLoadKeySet_Rotation(t *testing.T) {
+	// Arrange
+	oldKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	newKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	oldKeySet, err := LoadKeySet(&config.Config{
+		JWTAlgorithm:    "ES256",
+		JWTSigningKey:   writeKey(t, "old.pem", oldKey, true),
+		JWTSigningKeyID: "2024",
+	})
+	assert.NoError(t, err)
+	keySet, err := LoadKeySet(&config.Config{
+		JWTAlgorithm:     "ES256",
+		JWTSigningKey:    writeKey(t, "new.pem", newKey, true),
+		JWTSigningKeyID:  "2025",
+		JWTVerifyingKeys: []string{"2024=" + writeKey(t, "old.pub", oldKey, false)},
+	})
+	assert.NoError(t, err)
+	oldToken, err := oldKeySet.Sign(&jwt.StandardClaims{})
+	assert.NoError(t, err)
+
+	// Act
+	token, err := jwt.Parse(oldToken, keySet.Keyfunc)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, token.Valid)
+	jwks := keySet.JWKS()
+	assert.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "2024", jwks.Keys[0].Kid)
+	assert.Equal(t, "2025", jwks.Keys[1].Kid)
+
+	// Act
+	_, err = jwt.Parse(oldToken, NewHMACKeySet([]byte("secret")).Keyfunc)
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestHMACKeySet(t *testing.T) {
+	// Arrange
+	keySet := NewHMACKeySet([]byte("secret"))
+	legacyToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &jwt.StandardClaims{}).SignedString([]byte("secret"))
+	assert.NoError(t, err)
+	noneToken, err := jwt.NewWithClaims(jwt.SigningMethodNone, &jwt.StandardClaims{}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	assert.NoError(t, err)
+
+	// Act
+	legacy, legacyErr := jwt.Parse(legacyToken, keySet.Keyfunc)
+	_, noneErr := jwt.Parse(noneToken, keySet.Keyfunc)
+
+	// Assert
+	assert.NoError(t, legacyErr)
+	assert.True(t, legacy.Valid)
+	assert.Error(t, noneErr)
+	assert.Empty(t, keySet.JWKS().Keys)
+}
+
+func TestLoadKeySet_AlgorithmMismatch(t *testing.T) {
+	// Arrange
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	// Act
+	_, err := LoadKeySet(&config.Config{
+		JWTAlgorithm:  "RS256",
+		JWTSigningKey: writeKey(t, "signing.pem", ecKey, true),
+	})
+
+	// Assert
+	assert.Error(t, err)
+}
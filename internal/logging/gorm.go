@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+type gormLogger struct {
+	slowThreshold time.Duration
+}
+
+// NewGormLogger writes gorm messages, failed and slow queries to slog with the request attributes of the query context.
+// Queries are logged without their bound values, so hashes of passwords and tokens stay out of the log
+func NewGormLogger(slowThreshold time.Duration) gormlogger.Interface {
+	return &gormLogger{slowThreshold: slowThreshold}
+}
+
+func (gl *gormLogger) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	return gl
+}
+
+func (gl *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	slog.InfoContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (gl *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	slog.WarnContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (gl *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	slog.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (gl *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		slog.ErrorContext(ctx, "query failed", "error", err, "sql", sql, "rows", rows, "duration_ms", elapsed.Milliseconds())
+	case gl.slowThreshold > 0 && elapsed > gl.slowThreshold:
+		sql, rows := fc()
+		slog.WarnContext(ctx, "slow query", "sql", sql, "rows", rows, "duration_ms", elapsed.Milliseconds())
+	default:
+		if slog.Default().Enabled(ctx, slog.LevelDebug) {
+			sql, rows := fc()
+			slog.DebugContext(ctx, "query", "sql", sql, "rows", rows, "duration_ms", elapsed.Milliseconds())
+		}
+	}
+}
+
+func (gl *gormLogger) ParamsFilter(_ context.Context, sql string, _ ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are the attribute key fragments whose values are never written to the log
+var sensitiveKeys = []string{"password", "token", "secret", "authorization", "cookie", "invite"}
+
+type contextKey struct{}
+
+// New builds a logger writing in the given format, json or text, that adds the attributes stored in the context
+// with With to every record and redacts values of sensitive attributes
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	var logLevel slog.Level
+	if err := logLevel.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level)
+	}
+
+	options := &slog.HandlerOptions{Level: logLevel, ReplaceAttr: redact}
+	var handler slog.Handler
+	switch format {
+	case "json":
+		handler = slog.NewJSONHandler(w, options)
+	case "text":
+		handler = slog.NewTextHandler(w, options)
+	default:
+		return nil, fmt.Errorf("invalid log format %q", format)
+	}
+	return slog.New(&contextHandler{Handler: handler}), nil
+}
+
+// With returns a context whose log records carry the given key-value pairs in addition to the ones already stored
+func With(ctx context.Context, args ...any) context.Context {
+	attrs := append(attrsFrom(ctx), slog.Group("", args...).Value.Group()...)
+	return context.WithValue(ctx, contextKey{}, attrs)
+}
+
+func attrsFrom(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(contextKey{}).([]slog.Attr)
+	return attrs[:len(attrs):len(attrs)]
+}
+
+type contextHandler struct {
+	slog.Handler
+}
+
+func (ch *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		record.AddAttrs(attrsFrom(ctx)...)
+	}
+	return ch.Handler.Handle(ctx, record)
+}
+
+func (ch *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: ch.Handler.WithAttrs(attrs)}
+}
+
+func (ch *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: ch.Handler.WithGroup(name)}
+}
+
+func redact(_ []string, attr slog.Attr) slog.Attr {
+	key := strings.ToLower(attr.Key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return slog.String(attr.Key, redacted)
+		}
+	}
+	return attr
+}
@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNew_AddsContextAttributes(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	logger, err := New(&buf, "info", "json")
+	assert.NoError(t, err)
+	ctx := With(context.Background(), "request_id", "req-1")
+	ctx = With(ctx, "user_id", 7)
+
+	// Act
+	logger.InfoContext(ctx, "purchase failed", "item", "cup")
+
+	// Assert
+	var record map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "purchase failed", record["msg"])
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, float64(7), record["user_id"])
+	assert.Equal(t, "cup", record["item"])
+}
+
+func TestWith_DoesNotChangeParentContext(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	logger, _ := New(&buf, "info", "json")
+	parent := With(context.Background(), "request_id", "req-1")
+	_ = With(parent, "user_id", 1)
+	_ = With(parent, "user_id", 2)
+
+	// Act
+	logger.InfoContext(parent, "done")
+
+	// Assert
+	assert.NotContains(t, buf.String(), "user_id")
+}
+
+func TestNew_RedactsSensitiveAttributes(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	logger, _ := New(&buf, "info", "text")
+
+	// Act
+	logger.Info("login", "username", "alice", "password", "hunter22", "refresh_token", "abc.def", "Authorization", "Bearer xyz")
+
+	// Assert
+	assert.Contains(t, buf.String(), "alice")
+	assert.NotContains(t, buf.String(), "hunter22")
+	assert.NotContains(t, buf.String(), "abc.def")
+	assert.NotContains(t, buf.String(), "xyz")
+	assert.Contains(t, buf.String(), redacted)
+}
+
+func TestNew_FiltersByLevel(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	logger, _ := New(&buf, "warn", "json")
+
+	// Act
+	logger.Info("ignored")
+	logger.Warn("kept")
+
+	// Assert
+	assert.NotContains(t, buf.String(), "ignored")
+	assert.Contains(t, buf.String(), "kept")
+}
+
+func TestNew_RejectsInvalidSettings(t *testing.T) {
+	// Act
+	_, levelErr := New(&bytes.Buffer{}, "loud", "json")
+	_, formatErr := New(&bytes.Buffer{}, "info", "xml")
+
+	// Assert
+	assert.Error(t, levelErr)
+	assert.Error(t, formatErr)
+}
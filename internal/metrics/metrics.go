@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "merch_store"
+
+// Metrics holds the collectors of the service, registered in their own registry so tests can create them freely
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	HTTPRequestDuration *prometheus.HistogramVec
+
+	Purchases        *prometheus.CounterVec
+	CoinsSpent       prometheus.Counter
+	PurchaseFailures *prometheus.CounterVec
+	Transfers        prometheus.Counter
+	TransferVolume   prometheus.Counter
+	Registrations    *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		HTTPRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of HTTP requests by method, route and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		Purchases: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "purchases_total",
+			Help:      "Merch items bought, by item.",
+		}, []string{"item"}),
+		CoinsSpent: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "coins_spent_total",
+			Help:      "Coins spent on merch.",
+		}),
+		PurchaseFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "purchase_failures_total",
+			Help:      "Failed purchases and orders, by error code.",
+		}, []string{"code"}),
+		Transfers: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "coin_transfers_total",
+			Help:      "Coin transfers between users.",
+		}),
+		TransferVolume: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "coin_transfer_volume_total",
+			Help:      "Coins transferred between users.",
+		}),
+		Registrations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "user_registrations_total",
+			Help:      "New users, by the endpoint that created them.",
+		}, []string{"source"}),
+	}
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.HTTPRequestDuration,
+		m.Purchases,
+		m.CoinsSpent,
+		m.PurchaseFailures,
+		m.Transfers,
+		m.TransferVolume,
+		m.Registrations,
+	)
+	return m
+}
+
+// RegisterDB exposes the statistics of the database connection pool
+func (m *Metrics) RegisterDB(db *sql.DB) error {
+	return m.Registry.Register(collectors.NewDBStatsCollector(db, namespace))
+}
@@ -0,0 +1,229 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockKey identifies merch_store migrations among other advisory lock users of the database
+const lockKey int64 = 0x6d65726368
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Load reads the embedded migrations ordered by version, each of them must have both an up and a down script
+func Load() ([]Migration, error) {
+	entries, err := fs.ReadDir(files, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(files, "sql/"+entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both up and down scripts", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up applies every pending migration and returns the applied ones
+func (m *Migrator) Up() ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(func(ctx context.Context, conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			err := runInTx(ctx, conn, migration.Up,
+				"INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", migration.Version, migration.Name)
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the latest steps applied migrations and returns the rolled back ones
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	return m.revert(func(_ Migration, reverted int) bool { return reverted < steps })
+}
+
+// DownTo rolls back every applied migration newer than version and returns the rolled back ones
+func (m *Migrator) DownTo(version int) ([]Migration, error) {
+	return m.revert(func(migration Migration, _ int) bool { return migration.Version > version })
+}
+
+// revert rolls back applied migrations from the latest one for as long as next allows
+func (m *Migrator) revert(next func(migration Migration, reverted int) bool) ([]Migration, error) {
+	var reverted []Migration
+	err := m.withLock(func(ctx context.Context, conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && next(m.migrations[i], len(reverted)); i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			err := runInTx(ctx, conn, migration.Down,
+				"DELETE FROM schema_migrations WHERE version = $1", migration.Version)
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+func (m *Migrator) Status() ([]Status, error) {
+	var statuses []Status
+	err := m.withLock(func(ctx context.Context, conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			status := Status{Version: migration.Version, Name: migration.Name}
+			if appliedAt, ok := done[migration.Version]; ok {
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// withLock holds a session advisory lock on a dedicated connection, so replicas starting together migrate one at a time
+func (m *Migrator) withLock(fn func(ctx context.Context, conn *sql.Conn) error) error {
+	ctx := context.Background()
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockKey)
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint PRIMARY KEY,
+		name text NOT NULL,
+		applied_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return err
+	}
+	return fn(ctx, conn)
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+func runInTx(ctx context.Context, conn *sql.Conn, script, bookkeeping string, args ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, bookkeeping, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,22 @@
+package migration
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	// Act
+	migrations, err := Load()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+	for i, migration := range migrations {
+		assert.Equal(t, i+1, migration.Version, "versions must be consecutive")
+		assert.NotEmpty(t, migration.Name)
+		assert.NotEmpty(t, migration.Up)
+		assert.NotEmpty(t, migration.Down)
+	}
+	assert.Equal(t, "initial_schema", migrations[0].Name)
+}
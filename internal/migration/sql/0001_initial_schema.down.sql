@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS coin_transfers;
+DROP TABLE IF EXISTS purchases;
+DROP TABLE IF EXISTS merches;
+DROP TABLE IF EXISTS users;
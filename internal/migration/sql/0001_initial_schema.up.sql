@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS users (
+    id bigserial PRIMARY KEY,
+    username text NOT NULL CONSTRAINT uni_users_username UNIQUE,
+    password text NOT NULL,
+    coins bigint NOT NULL DEFAULT 1000
+);
+
+CREATE TABLE IF NOT EXISTS merches (
+    name text PRIMARY KEY,
+    price bigint NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS purchases (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    merch_item text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS coin_transfers (
+    id bigserial PRIMARY KEY,
+    from_user_id bigint NOT NULL,
+    to_user_id bigint NOT NULL,
+    amount bigint NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
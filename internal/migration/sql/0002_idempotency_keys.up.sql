@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id bigint NOT NULL,
+    key varchar(255) NOT NULL,
+    request_hash text NOT NULL,
+    status_code bigint NOT NULL DEFAULT 0,
+    response_body bytea,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, key)
+);
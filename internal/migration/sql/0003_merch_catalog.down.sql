@@ -0,0 +1,2 @@
+ALTER TABLE merches DROP COLUMN IF EXISTS stock;
+ALTER TABLE merches DROP COLUMN IF EXISTS retired;
@@ -0,0 +1,2 @@
+ALTER TABLE merches ADD COLUMN IF NOT EXISTS retired boolean NOT NULL DEFAULT false;
+ALTER TABLE merches ADD COLUMN IF NOT EXISTS stock bigint;
@@ -0,0 +1,4 @@
+ALTER TABLE purchases DROP CONSTRAINT IF EXISTS fk_orders_purchases;
+ALTER TABLE purchases DROP COLUMN IF EXISTS quantity;
+ALTER TABLE purchases DROP COLUMN IF EXISTS order_id;
+DROP TABLE IF EXISTS orders;
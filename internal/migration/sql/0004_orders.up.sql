@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS orders (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    total bigint NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS order_id bigint;
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS quantity bigint NOT NULL DEFAULT 1;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_orders_purchases') THEN
+        ALTER TABLE purchases ADD CONSTRAINT fk_orders_purchases FOREIGN KEY (order_id) REFERENCES orders (id);
+    END IF;
+END $$;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS coin_adjustments (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    admin_id bigint NOT NULL,
+    type text NOT NULL,
+    amount bigint NOT NULL,
+    reason text NOT NULL,
+    reference text,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_coin_adjustments_user_id ON coin_adjustments (user_id);
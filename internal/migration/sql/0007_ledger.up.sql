@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+    code text PRIMARY KEY,
+    type text NOT NULL,
+    user_id bigint,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_accounts_user_id ON ledger_accounts (user_id);
+
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id bigserial PRIMARY KEY,
+    type text NOT NULL,
+    reference text,
+    created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_journal_entries_reference ON journal_entries (reference);
+
+CREATE TABLE IF NOT EXISTS postings (
+    id bigserial PRIMARY KEY,
+    journal_entry_id bigint NOT NULL,
+    account_code text NOT NULL,
+    amount bigint NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_postings_journal_entry_id ON postings (journal_entry_id);
+CREATE INDEX IF NOT EXISTS idx_postings_account_code ON postings (account_code);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_journal_entries_postings') THEN
+        ALTER TABLE postings ADD CONSTRAINT fk_journal_entries_postings FOREIGN KEY (journal_entry_id) REFERENCES journal_entries (id);
+    END IF;
+END $$;
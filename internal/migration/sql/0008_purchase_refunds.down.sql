@@ -0,0 +1,3 @@
+ALTER TABLE purchases DROP COLUMN IF EXISTS refund_reason;
+ALTER TABLE purchases DROP COLUMN IF EXISTS refunded_by;
+ALTER TABLE purchases DROP COLUMN IF EXISTS refunded_at;
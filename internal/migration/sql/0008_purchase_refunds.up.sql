@@ -0,0 +1,3 @@
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS refunded_at timestamptz;
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS refunded_by bigint;
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS refund_reason text;
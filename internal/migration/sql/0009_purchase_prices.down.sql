@@ -0,0 +1,3 @@
+ALTER TABLE purchases DROP COLUMN IF EXISTS price_estimated;
+ALTER TABLE purchases DROP COLUMN IF EXISTS total;
+ALTER TABLE purchases DROP COLUMN IF EXISTS unit_price;
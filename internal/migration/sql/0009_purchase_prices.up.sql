@@ -0,0 +1,19 @@
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS unit_price bigint NOT NULL DEFAULT 0;
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS total bigint NOT NULL DEFAULT 0;
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS price_estimated boolean NOT NULL DEFAULT false;
+
+-- The amount charged in the ledger is exact
+UPDATE purchases
+SET total = -postings.amount, unit_price = -postings.amount / purchases.quantity
+FROM journal_entries
+JOIN postings ON postings.journal_entry_id = journal_entries.id
+WHERE purchases.unit_price = 0
+    AND journal_entries.type = 'purchase'
+    AND journal_entries.reference = CONCAT('purchase:', purchases.id)
+    AND postings.account_code = CONCAT('user:', purchases.user_id);
+
+-- Purchases older than the ledger fall back to today's price
+UPDATE purchases
+SET unit_price = merches.price, total = merches.price * purchases.quantity, price_estimated = true
+FROM merches
+WHERE purchases.unit_price = 0 AND merches.name = purchases.merch_item;
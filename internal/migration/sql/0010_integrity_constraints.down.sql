@@ -0,0 +1,31 @@
+ALTER TABLE postings DROP CONSTRAINT IF EXISTS fk_postings_account;
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS fk_ledger_accounts_user;
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS fk_idempotency_keys_user;
+ALTER TABLE refresh_tokens DROP CONSTRAINT IF EXISTS fk_refresh_tokens_user;
+
+ALTER TABLE coin_adjustments DROP CONSTRAINT IF EXISTS fk_coin_adjustments_admin;
+ALTER TABLE coin_adjustments DROP CONSTRAINT IF EXISTS fk_coin_adjustments_user;
+ALTER TABLE coin_adjustments DROP CONSTRAINT IF EXISTS chk_coin_adjustments_amount;
+
+DROP INDEX IF EXISTS idx_purchases_user_id;
+ALTER TABLE purchases DROP CONSTRAINT IF EXISTS fk_purchases_merch;
+ALTER TABLE purchases DROP CONSTRAINT IF EXISTS fk_purchases_user;
+ALTER TABLE purchases DROP CONSTRAINT IF EXISTS chk_purchases_quantity;
+
+DROP INDEX IF EXISTS idx_orders_user_id;
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS fk_orders_user;
+
+DROP INDEX IF EXISTS idx_coin_transfers_to_user_id;
+DROP INDEX IF EXISTS idx_coin_transfers_from_user_id;
+ALTER TABLE coin_transfers DROP CONSTRAINT IF EXISTS fk_coin_transfers_to_user;
+ALTER TABLE coin_transfers DROP CONSTRAINT IF EXISTS fk_coin_transfers_from_user;
+ALTER TABLE coin_transfers DROP CONSTRAINT IF EXISTS chk_coin_transfers_distinct_users;
+ALTER TABLE coin_transfers DROP CONSTRAINT IF EXISTS chk_coin_transfers_amount;
+
+ALTER TABLE merches DROP CONSTRAINT IF EXISTS chk_merches_stock;
+ALTER TABLE merches DROP CONSTRAINT IF EXISTS chk_merches_price;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS chk_users_coins;
+
+-- quarantined_rows is kept, it holds the only copy of the rows moved aside by the up migration
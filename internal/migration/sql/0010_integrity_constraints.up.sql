@@ -0,0 +1,114 @@
+-- Rows written before these rules were enforced are repaired or moved aside first, otherwise adding the constraints
+-- fails on an existing database. Every changed or removed row is copied to quarantined_rows for review
+CREATE TABLE IF NOT EXISTS quarantined_rows (
+    id bigserial PRIMARY KEY,
+    table_name text NOT NULL,
+    reason text NOT NULL,
+    row_data jsonb NOT NULL,
+    quarantined_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The ledger keeps the real balance, --reconcile reports the users whose cached balance was reset
+INSERT INTO quarantined_rows (table_name, reason, row_data)
+SELECT 'users', 'negative balance reset to 0', to_jsonb(users) FROM users WHERE coins < 0;
+UPDATE users SET coins = 0 WHERE coins < 0;
+
+INSERT INTO quarantined_rows (table_name, reason, row_data)
+SELECT 'merches', 'non-positive price, retired with price 1', to_jsonb(merches) FROM merches WHERE price <= 0;
+UPDATE merches SET price = 1, retired = true WHERE price <= 0;
+INSERT INTO quarantined_rows (table_name, reason, row_data)
+SELECT 'merches', 'negative stock reset to 0', to_jsonb(merches) FROM merches WHERE stock < 0;
+UPDATE merches SET stock = 0 WHERE stock < 0;
+
+-- Purchases of items missing from the catalog keep their history through a retired placeholder item
+WITH placeholders AS (
+    INSERT INTO merches (name, price, retired)
+    SELECT merch_item, GREATEST(MAX(unit_price), 1), true FROM purchases
+    WHERE NOT EXISTS (SELECT 1 FROM merches WHERE merches.name = purchases.merch_item)
+    GROUP BY merch_item
+    RETURNING *
+)
+INSERT INTO quarantined_rows (table_name, reason, row_data)
+SELECT 'merches', 'retired placeholder for purchases of an unknown item', to_jsonb(placeholders) FROM placeholders;
+
+WITH moved AS (
+    DELETE FROM coin_transfers
+    WHERE amount <= 0 OR from_user_id = to_user_id
+        OR NOT EXISTS (SELECT 1 FROM users WHERE users.id = coin_transfers.from_user_id)
+        OR NOT EXISTS (SELECT 1 FROM users WHERE users.id = coin_transfers.to_user_id)
+    RETURNING *
+)
+INSERT INTO quarantined_rows (table_name, reason, row_data)
+SELECT 'coin_transfers', 'non-positive amount, self-transfer or unknown user', to_jsonb(moved) FROM moved;
+
+WITH moved AS (
+    DELETE FROM purchases
+    WHERE quantity <= 0
+        OR NOT EXISTS (SELECT 1 FROM users WHERE users.id = purchases.user_id)
+        OR order_id IN (SELECT id FROM orders WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = orders.user_id))
+    RETURNING *
+)
+INSERT INTO quarantined_rows (table_name, reason, row_data)
+SELECT 'purchases', 'non-positive quantity or unknown user', to_jsonb(moved) FROM moved;
+
+WITH moved AS (
+    DELETE FROM orders WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = orders.user_id)
+    RETURNING *
+)
+INSERT INTO quarantined_rows (table_name, reason, row_data)
+SELECT 'orders', 'unknown user', to_jsonb(moved) FROM moved;
+
+WITH moved AS (
+    DELETE FROM coin_adjustments
+    WHERE amount <= 0
+        OR NOT EXISTS (SELECT 1 FROM users WHERE users.id = coin_adjustments.user_id)
+        OR NOT EXISTS (SELECT 1 FROM users WHERE users.id = coin_adjustments.admin_id)
+    RETURNING *
+)
+INSERT INTO quarantined_rows (table_name, reason, row_data)
+SELECT 'coin_adjustments', 'non-positive amount or unknown user', to_jsonb(moved) FROM moved;
+
+-- Postings still reference the account, so only its link to the missing user is dropped
+INSERT INTO quarantined_rows (table_name, reason, row_data)
+SELECT 'ledger_accounts', 'unknown user unlinked', to_jsonb(ledger_accounts) FROM ledger_accounts
+WHERE user_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM users WHERE users.id = ledger_accounts.user_id);
+UPDATE ledger_accounts SET user_id = NULL
+WHERE user_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM users WHERE users.id = ledger_accounts.user_id);
+
+DELETE FROM refresh_tokens WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = refresh_tokens.user_id);
+DELETE FROM idempotency_keys WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = idempotency_keys.user_id);
+
+ALTER TABLE users ADD CONSTRAINT chk_users_coins CHECK (coins >= 0);
+
+ALTER TABLE merches ADD CONSTRAINT chk_merches_price CHECK (price > 0);
+ALTER TABLE merches ADD CONSTRAINT chk_merches_stock CHECK (stock >= 0);
+
+ALTER TABLE coin_transfers ADD CONSTRAINT chk_coin_transfers_amount CHECK (amount > 0);
+ALTER TABLE coin_transfers ADD CONSTRAINT chk_coin_transfers_distinct_users CHECK (from_user_id <> to_user_id);
+ALTER TABLE coin_transfers ADD CONSTRAINT fk_coin_transfers_from_user
+    FOREIGN KEY (from_user_id) REFERENCES users (id) ON DELETE RESTRICT;
+ALTER TABLE coin_transfers ADD CONSTRAINT fk_coin_transfers_to_user
+    FOREIGN KEY (to_user_id) REFERENCES users (id) ON DELETE RESTRICT;
+CREATE INDEX IF NOT EXISTS idx_coin_transfers_from_user_id ON coin_transfers (from_user_id);
+CREATE INDEX IF NOT EXISTS idx_coin_transfers_to_user_id ON coin_transfers (to_user_id);
+
+ALTER TABLE orders ADD CONSTRAINT fk_orders_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE RESTRICT;
+CREATE INDEX IF NOT EXISTS idx_orders_user_id ON orders (user_id);
+
+-- Merch is retired rather than deleted, a rename carries its purchases along
+ALTER TABLE purchases ADD CONSTRAINT chk_purchases_quantity CHECK (quantity > 0);
+ALTER TABLE purchases ADD CONSTRAINT fk_purchases_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE RESTRICT;
+ALTER TABLE purchases ADD CONSTRAINT fk_purchases_merch
+    FOREIGN KEY (merch_item) REFERENCES merches (name) ON UPDATE CASCADE ON DELETE RESTRICT;
+CREATE INDEX IF NOT EXISTS idx_purchases_user_id ON purchases (user_id);
+
+ALTER TABLE coin_adjustments ADD CONSTRAINT chk_coin_adjustments_amount CHECK (amount > 0);
+ALTER TABLE coin_adjustments ADD CONSTRAINT fk_coin_adjustments_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE RESTRICT;
+ALTER TABLE coin_adjustments ADD CONSTRAINT fk_coin_adjustments_admin FOREIGN KEY (admin_id) REFERENCES users (id) ON DELETE RESTRICT;
+
+-- Sessions and stored responses are worthless without their user
+ALTER TABLE refresh_tokens ADD CONSTRAINT fk_refresh_tokens_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE idempotency_keys ADD CONSTRAINT fk_idempotency_keys_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+
+ALTER TABLE ledger_accounts ADD CONSTRAINT fk_ledger_accounts_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE RESTRICT;
+ALTER TABLE postings ADD CONSTRAINT fk_postings_account FOREIGN KEY (account_code) REFERENCES ledger_accounts (code) ON DELETE RESTRICT;
@@ -0,0 +1 @@
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS content_type text NOT NULL DEFAULT '';
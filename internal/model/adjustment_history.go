@@ -0,0 +1,10 @@
+package model
+
+import "github.com/ners1us/merch_store/internal/enum"
+
+type AdjustmentHistory struct {
+	Type      enum.AdjustmentType `json:"type"`
+	Amount    int                 `json:"amount"`
+	Reason    string              `json:"reason"`
+	Reference string              `json:"reference,omitempty"`
+}
@@ -0,0 +1,7 @@
+package model
+
+type AdjustmentRequest struct {
+	Amount    int    `json:"amount"`
+	Reason    string `json:"reason"`
+	Reference string `json:"reference"`
+}
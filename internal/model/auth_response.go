@@ -1,5 +1,9 @@
 package model
 
 type AuthResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	// Registered tells whether the user was created by this request
+	Registered bool `json:"-"`
 }
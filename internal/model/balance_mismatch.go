@@ -0,0 +1,8 @@
+package model
+
+type BalanceMismatch struct {
+	UserID      int    `json:"user_id"`
+	Username    string `json:"username"`
+	CachedCoins int    `json:"cached_coins"`
+	LedgerCoins int    `json:"ledger_coins"`
+}
@@ -0,0 +1,10 @@
+package model
+
+import "github.com/ners1us/merch_store/internal/enum"
+
+type CatalogChange struct {
+	Name     string          `json:"name"`
+	Action   enum.SeedAction `json:"action"`
+	OldPrice int             `json:"old_price"`
+	NewPrice int             `json:"new_price"`
+}
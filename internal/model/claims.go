@@ -1,9 +1,14 @@
 package model
 
-import "github.com/golang-jwt/jwt"
+import (
+	"github.com/golang-jwt/jwt"
+	"github.com/ners1us/merch_store/internal/enum"
+)
 
 type Claims struct {
-	Username string `json:"username"`
-	UserID   int    `json:"user_id"`
+	Username string      `json:"username"`
+	UserID   int         `json:"user_id"`
+	Role     enum.Role   `json:"role"`
+	Locale   enum.Locale `json:"locale,omitempty"`
 	jwt.StandardClaims
 }
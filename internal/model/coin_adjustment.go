@@ -0,0 +1,17 @@
+package model
+
+import (
+	"github.com/ners1us/merch_store/internal/enum"
+	"time"
+)
+
+type CoinAdjustment struct {
+	ID        int                 `gorm:"primaryKey" json:"id"`
+	UserID    int                 `gorm:"not null;index" json:"user_id"`
+	AdminID   int                 `gorm:"not null" json:"admin_id"`
+	Type      enum.AdjustmentType `gorm:"not null" json:"type"`
+	Amount    int                 `gorm:"not null" json:"amount"`
+	Reason    string              `gorm:"not null" json:"reason"`
+	Reference string              `json:"reference,omitempty"`
+	CreatedAt time.Time           `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
@@ -1,6 +1,7 @@
 package model
 
 type CoinHistory struct {
-	Received []ReceivedCoinHistory `json:"received"`
-	Sent     []SentCoinHistory     `json:"sent"`
+	Received    []ReceivedCoinHistory `json:"received"`
+	Sent        []SentCoinHistory     `json:"sent"`
+	Adjustments []AdjustmentHistory   `json:"adjustments"`
 }
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"github.com/ners1us/merch_store/internal/enum"
+	"time"
+)
+
+type HistoryFilter struct {
+	Direction    enum.Direction
+	Counterparty string
+	Types        []enum.EntryType
+	From         time.Time
+	To           time.Time
+	Cursor       *HistoryCursor
+	Limit        int
+}
+
+type HistoryCursor struct {
+	CreatedAt time.Time
+	ID        int
+}
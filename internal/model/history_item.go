@@ -0,0 +1,21 @@
+package model
+
+import (
+	"github.com/ners1us/merch_store/internal/enum"
+	"time"
+)
+
+type HistoryItem struct {
+	ID             int            `json:"id"`
+	Type           enum.EntryType `json:"type"`
+	Direction      enum.Direction `json:"direction"`
+	Amount         int            `json:"amount"`
+	Counterparty   string         `json:"counterparty,omitempty"`
+	Item           string         `json:"item,omitempty"`
+	Quantity       int            `json:"quantity,omitempty"`
+	UnitPrice      int            `json:"unit_price,omitempty"`
+	Total          int            `json:"total,omitempty"`
+	PriceEstimated bool           `json:"price_estimated,omitempty"`
+	Reference      string         `json:"reference,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+type HistoryRequest struct {
+	Direction    string    `form:"direction"`
+	Counterparty string    `form:"counterparty"`
+	Type         string    `form:"type"`
+	From         time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To           time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor       string    `form:"cursor"`
+	Limit        int       `form:"limit"`
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+type IdempotencyKey struct {
+	UserID       int       `gorm:"primaryKey" json:"user_id"`
+	Key          string    `gorm:"primaryKey;size:255" json:"key"`
+	RequestHash  string    `gorm:"not null" json:"request_hash"`
+	StatusCode   int       `gorm:"not null;default:0" json:"status_code"`
+	ContentType  string    `gorm:"not null;default:''" json:"content_type"`
+	ResponseBody []byte    `json:"-"`
+	CreatedAt    time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
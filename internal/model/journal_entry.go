@@ -0,0 +1,37 @@
+package model
+
+import (
+	"github.com/ners1us/merch_store/internal/enum"
+	"time"
+)
+
+type JournalEntry struct {
+	ID        int             `gorm:"primaryKey" json:"id"`
+	Type      enum.EntryType  `gorm:"not null" json:"type"`
+	Reference string          `gorm:"index" json:"reference,omitempty"`
+	CreatedAt time.Time       `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	Postings  []Posting       `gorm:"foreignKey:JournalEntryID" json:"postings"`
+	Accounts  []LedgerAccount `gorm:"-" json:"-"`
+}
+
+// NewJournalEntry moves amount coins from one account to another
+func NewJournalEntry(entryType enum.EntryType, reference string, from, to LedgerAccount, amount int) *JournalEntry {
+	return &JournalEntry{
+		Type:      entryType,
+		Reference: reference,
+		CreatedAt: time.Now(),
+		Postings: []Posting{
+			{AccountCode: from.Code, Amount: -amount},
+			{AccountCode: to.Code, Amount: amount},
+		},
+		Accounts: []LedgerAccount{from, to},
+	}
+}
+
+func (je *JournalEntry) Balanced() bool {
+	sum := 0
+	for _, posting := range je.Postings {
+		sum += posting.Amount
+	}
+	return len(je.Postings) >= 2 && sum == 0
+}
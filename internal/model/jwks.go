@@ -0,0 +1,5 @@
+package model
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"fmt"
+	"github.com/ners1us/merch_store/internal/enum"
+	"time"
+)
+
+const (
+	IssuanceAccountCode   = "system:issuance"
+	StoreAccountCode      = "system:store"
+	AdjustmentAccountCode = "system:adjustments"
+)
+
+type LedgerAccount struct {
+	Code      string           `gorm:"primaryKey" json:"code"`
+	Type      enum.AccountType `gorm:"not null" json:"type"`
+	UserID    *int             `gorm:"uniqueIndex" json:"user_id,omitempty"`
+	CreatedAt time.Time        `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+func UserAccount(userID int) LedgerAccount {
+	return LedgerAccount{Code: fmt.Sprintf("user:%d", userID), Type: enum.AccountUser, UserID: &userID}
+}
+
+func SystemAccount(code string) LedgerAccount {
+	return LedgerAccount{Code: code, Type: enum.AccountSystem}
+}
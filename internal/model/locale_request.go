@@ -0,0 +1,7 @@
+package model
+
+import "github.com/ners1us/merch_store/internal/enum"
+
+type LocaleRequest struct {
+	Locale enum.Locale `json:"locale"`
+}
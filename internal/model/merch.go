@@ -1,6 +1,8 @@
 package model
 
 type Merch struct {
-	Name  string `gorm:"primaryKey;not null" json:"name"`
-	Price int    `gorm:"not null" json:"price"`
+	Name    string `gorm:"primaryKey;not null" json:"name"`
+	Price   int    `gorm:"not null" json:"price"`
+	Stock   *int   `json:"stock"`
+	Retired bool   `gorm:"not null;default:false" json:"-"`
 }
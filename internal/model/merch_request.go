@@ -0,0 +1,6 @@
+package model
+
+type MerchRequest struct {
+	Price int  `json:"price"`
+	Stock *int `json:"stock"`
+}
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+type Order struct {
+	ID        int        `gorm:"primaryKey" json:"id"`
+	UserID    int        `gorm:"not null" json:"user_id"`
+	Total     int        `gorm:"not null" json:"total"`
+	CreatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	Purchases []Purchase `gorm:"foreignKey:OrderID" json:"items"`
+}
@@ -0,0 +1,6 @@
+package model
+
+type OrderLine struct {
+	Item     string `json:"item"`
+	Quantity int    `json:"quantity"`
+}
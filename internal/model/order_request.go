@@ -0,0 +1,5 @@
+package model
+
+type OrderRequest struct {
+	Items []OrderLine `json:"items"`
+}
@@ -0,0 +1,8 @@
+package model
+
+type Posting struct {
+	ID             int    `gorm:"primaryKey" json:"id"`
+	JournalEntryID int    `gorm:"not null;index" json:"journal_entry_id"`
+	AccountCode    string `gorm:"not null;index" json:"account_code"`
+	Amount         int    `gorm:"not null" json:"amount"`
+}
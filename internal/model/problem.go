@@ -0,0 +1,12 @@
+package model
+
+// Problem is an RFC 7807 problem details body, Error repeats Detail for clients of the former {"error": ...} format
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+	Error    string `json:"error"`
+}
@@ -3,8 +3,16 @@ package model
 import "time"
 
 type Purchase struct {
-	ID        int       `gorm:"primaryKey" json:"id"`
-	UserID    int       `gorm:"not null" json:"user_id"`
-	MerchItem string    `gorm:"not null" json:"merch_item"`
-	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	ID             int        `gorm:"primaryKey" json:"id"`
+	UserID         int        `gorm:"not null" json:"user_id"`
+	OrderID        *int       `json:"order_id,omitempty"`
+	MerchItem      string     `gorm:"not null" json:"merch_item"`
+	Quantity       int        `gorm:"not null;default:1" json:"quantity"`
+	UnitPrice      int        `gorm:"not null;default:0" json:"unit_price"`
+	Total          int        `gorm:"not null;default:0" json:"total"`
+	PriceEstimated bool       `gorm:"not null;default:false" json:"price_estimated,omitempty"`
+	CreatedAt      time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	RefundedAt     *time.Time `json:"refunded_at,omitempty"`
+	RefundedBy     *int       `json:"refunded_by,omitempty"`
+	RefundReason   string     `json:"refund_reason,omitempty"`
 }
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+type RefreshToken struct {
+	ID        int        `gorm:"primaryKey" json:"id"`
+	UserID    int        `gorm:"not null;index" json:"user_id"`
+	FamilyID  string     `gorm:"not null;index" json:"family_id"`
+	TokenHash string     `gorm:"not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
@@ -0,0 +1,5 @@
+package model
+
+type RefundRequest struct {
+	Reason string `json:"reason"`
+}
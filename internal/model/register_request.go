@@ -0,0 +1,7 @@
+package model
+
+type RegisterRequest struct {
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	InviteCode string `json:"invite_code"`
+}
@@ -0,0 +1,8 @@
+package model
+
+import "time"
+
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey" json:"jti"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+}
@@ -1,8 +1,12 @@
 package model
 
+import "github.com/ners1us/merch_store/internal/enum"
+
 type User struct {
-	ID       int    `gorm:"primaryKey" json:"id"`
-	Username string `gorm:"unique;not null" json:"username"`
-	Password string `gorm:"not null" json:"-"`
-	Coins    int    `gorm:"not null;default:1000" json:"coins"`
+	ID       int         `gorm:"primaryKey" json:"id"`
+	Username string      `gorm:"unique;not null" json:"username"`
+	Password string      `gorm:"not null" json:"-"`
+	Coins    int         `gorm:"not null;default:1000" json:"coins"`
+	Role     enum.Role   `gorm:"not null;default:user" json:"role"`
+	Locale   enum.Locale `gorm:"not null;default:''" json:"locale,omitempty"`
 }
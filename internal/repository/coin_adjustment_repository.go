@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"github.com/ners1us/merch_store/internal/model"
+	"gorm.io/gorm"
+	"slices"
+)
+
+type CoinAdjustmentRepository interface {
+	Create(ctx context.Context, adjustment *model.CoinAdjustment) error
+	GetUserAdjustments(ctx context.Context, userID int, limit int) ([]model.AdjustmentHistory, error)
+	WithTx(tx *gorm.DB) CoinAdjustmentRepository
+}
+
+type coinAdjustmentRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewCoinAdjustmentRepository(db *gorm.DB) CoinAdjustmentRepository {
+	return &coinAdjustmentRepositoryImpl{db: db}
+}
+
+func (car *coinAdjustmentRepositoryImpl) Create(ctx context.Context, adjustment *model.CoinAdjustment) error {
+	return translateError(car.db.WithContext(ctx).Create(adjustment).Error)
+}
+
+func (car *coinAdjustmentRepositoryImpl) GetUserAdjustments(ctx context.Context, userID int, limit int) ([]model.AdjustmentHistory, error) {
+	var adjustments []model.AdjustmentHistory
+	err := latest(car.db.WithContext(ctx).Model(&model.CoinAdjustment{}), "coin_adjustments", limit).
+		Select("type, amount, reason, reference").
+		Where("user_id = ?", userID).
+		Scan(&adjustments).Error
+	slices.Reverse(adjustments)
+	return adjustments, err
+}
+
+func (car *coinAdjustmentRepositoryImpl) WithTx(tx *gorm.DB) CoinAdjustmentRepository {
+	return &coinAdjustmentRepositoryImpl{db: tx}
+}
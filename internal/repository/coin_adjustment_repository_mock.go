@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockCoinAdjustmentRepository struct {
+	mock.Mock
+}
+
+func NewMockCoinAdjustmentRepository() *MockCoinAdjustmentRepository {
+	return &MockCoinAdjustmentRepository{}
+}
+
+func (mcar *MockCoinAdjustmentRepository) Create(ctx context.Context, adjustment *model.CoinAdjustment) error {
+	args := mcar.Called(adjustment)
+	return args.Error(0)
+}
+
+func (mcar *MockCoinAdjustmentRepository) GetUserAdjustments(ctx context.Context, userID int, limit int) ([]model.AdjustmentHistory, error) {
+	args := mcar.Called(userID, limit)
+	return args.Get(0).([]model.AdjustmentHistory), args.Error(1)
+}
+
+func (mcar *MockCoinAdjustmentRepository) WithTx(tx *gorm.DB) CoinAdjustmentRepository {
+	return mcar
+}
@@ -1,14 +1,17 @@
 package repository
 
 import (
+	"context"
 	"github.com/ners1us/merch_store/internal/model"
 	"gorm.io/gorm"
+	"slices"
 )
 
 type CoinTransferRepository interface {
-	Create(transfer *model.CoinTransfer) error
-	GetReceivedTransfers(userID int) ([]model.ReceivedCoinHistory, error)
-	GetSentTransfers(userID int) ([]model.SentCoinHistory, error)
+	Create(ctx context.Context, transfer *model.CoinTransfer) error
+	GetReceivedTransfers(ctx context.Context, userID int, limit int) ([]model.ReceivedCoinHistory, error)
+	GetSentTransfers(ctx context.Context, userID int, limit int) ([]model.SentCoinHistory, error)
+	WithTx(tx *gorm.DB) CoinTransferRepository
 }
 
 type coinTransferRepositoryImpl struct {
@@ -19,26 +22,42 @@ func NewCoinTransferRepository(db *gorm.DB) CoinTransferRepository {
 	return &coinTransferRepositoryImpl{db: db}
 }
 
-func (ctr *coinTransferRepositoryImpl) Create(transfer *model.CoinTransfer) error {
-	return ctr.db.Create(transfer).Error
+func (ctr *coinTransferRepositoryImpl) Create(ctx context.Context, transfer *model.CoinTransfer) error {
+	return translateError(ctr.db.WithContext(ctx).Create(transfer).Error)
 }
 
-func (ctr *coinTransferRepositoryImpl) GetReceivedTransfers(userID int) ([]model.ReceivedCoinHistory, error) {
+// GetReceivedTransfers returns the latest limit transfers in chronological order, or all of them when limit is 0
+func (ctr *coinTransferRepositoryImpl) GetReceivedTransfers(ctx context.Context, userID int, limit int) ([]model.ReceivedCoinHistory, error) {
 	var received []model.ReceivedCoinHistory
-	err := ctr.db.Table("coin_transfers").
+	err := latest(ctr.db.WithContext(ctx).Table("coin_transfers"), "coin_transfers", limit).
 		Select("users.username as from_user, coin_transfers.amount").
 		Joins("join users on coin_transfers.from_user_id = users.id").
 		Where("coin_transfers.to_user_id = ?", userID).
 		Scan(&received).Error
+	slices.Reverse(received)
 	return received, err
 }
 
-func (ctr *coinTransferRepositoryImpl) GetSentTransfers(userID int) ([]model.SentCoinHistory, error) {
+func (ctr *coinTransferRepositoryImpl) GetSentTransfers(ctx context.Context, userID int, limit int) ([]model.SentCoinHistory, error) {
 	var sent []model.SentCoinHistory
-	err := ctr.db.Table("coin_transfers").
+	err := latest(ctr.db.WithContext(ctx).Table("coin_transfers"), "coin_transfers", limit).
 		Select("users.username as to_user, coin_transfers.amount").
 		Joins("join users on coin_transfers.to_user_id = users.id").
 		Where("coin_transfers.from_user_id = ?", userID).
 		Scan(&sent).Error
+	slices.Reverse(sent)
 	return sent, err
 }
+
+// latest orders the query newest first and keeps the first limit rows, all of them when limit is 0
+func latest(query *gorm.DB, table string, limit int) *gorm.DB {
+	query = query.Order(table + ".created_at DESC, " + table + ".id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	return query
+}
+
+func (ctr *coinTransferRepositoryImpl) WithTx(tx *gorm.DB) CoinTransferRepository {
+	return &coinTransferRepositoryImpl{db: tx}
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/ners1us/merch_store/internal/enum"
+)
+
+// constraintErrors maps the schema constraints to the errors services return when checking the same rules themselves.
+// chk_users_coins is shared by every debit, so it maps to the generic ErrInsufficientMoney and
+// services that report a more specific error, like purchases, translate it at the call site
+var constraintErrors = map[string]enum.ErrorType{
+	"uni_users_username":                enum.ErrUserAlreadyExists,
+	"chk_users_coins":                   enum.ErrInsufficientMoney,
+	"merches_pkey":                      enum.ErrItemAlreadyExists,
+	"chk_merches_price":                 enum.ErrInappropriatePrice,
+	"chk_merches_stock":                 enum.ErrInappropriateStock,
+	"chk_coin_transfers_amount":         enum.ErrCoinsInappropriateAmount,
+	"chk_coin_transfers_distinct_users": enum.ErrEqualReceivers,
+	"fk_coin_transfers_from_user":       enum.ErrUserNotFound,
+	"fk_coin_transfers_to_user":         enum.ErrReceiverNotFound,
+	"fk_orders_user":                    enum.ErrUserNotFound,
+	"chk_purchases_quantity":            enum.ErrInappropriateQuantity,
+	"fk_purchases_user":                 enum.ErrUserNotFound,
+	"fk_purchases_merch":                enum.ErrItemNotFound,
+	"chk_coin_adjustments_amount":       enum.ErrCoinsInappropriateAmount,
+	"fk_coin_adjustments_user":          enum.ErrUserNotFound,
+	"fk_coin_adjustments_admin":         enum.ErrUserNotFound,
+	"fk_ledger_accounts_user":           enum.ErrUserNotFound,
+}
+
+// translateError replaces a constraint violation with the matching enum error, other errors are returned as is
+func translateError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		if mapped, ok := constraintErrors[pgErr.ConstraintName]; ok {
+			return mapped
+		}
+	}
+	return err
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"github.com/ners1us/merch_store/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type IdempotencyKeyRepository interface {
+	Reserve(ctx context.Context, record *model.IdempotencyKey) (bool, error)
+	FindByKey(ctx context.Context, userID int, key string) (*model.IdempotencyKey, error)
+	Complete(ctx context.Context, userID int, key string, statusCode int, contentType string, body []byte) error
+	Delete(ctx context.Context, userID int, key string) error
+	DeleteExpired(ctx context.Context, userID int, key string, before time.Time) error
+	DeleteAbandoned(ctx context.Context, userID int, key string, reservedBefore time.Time) error
+}
+
+type idempotencyKeyRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyKeyRepository(db *gorm.DB) IdempotencyKeyRepository {
+	return &idempotencyKeyRepositoryImpl{db: db}
+}
+
+func (ikr *idempotencyKeyRepositoryImpl) Reserve(ctx context.Context, record *model.IdempotencyKey) (bool, error) {
+	result := ikr.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (ikr *idempotencyKeyRepositoryImpl) FindByKey(ctx context.Context, userID int, key string) (*model.IdempotencyKey, error) {
+	var record model.IdempotencyKey
+	err := ikr.db.WithContext(ctx).Where("user_id = ? AND key = ?", userID, key).First(&record).Error
+	return &record, err
+}
+
+func (ikr *idempotencyKeyRepositoryImpl) Complete(ctx context.Context, userID int, key string, statusCode int, contentType string, body []byte) error {
+	return ikr.db.WithContext(ctx).Model(&model.IdempotencyKey{}).
+		Where("user_id = ? AND key = ?", userID, key).
+		Updates(map[string]interface{}{"status_code": statusCode, "content_type": contentType, "response_body": body}).Error
+}
+
+func (ikr *idempotencyKeyRepositoryImpl) Delete(ctx context.Context, userID int, key string) error {
+	return ikr.db.WithContext(ctx).Where("user_id = ? AND key = ?", userID, key).Delete(&model.IdempotencyKey{}).Error
+}
+
+func (ikr *idempotencyKeyRepositoryImpl) DeleteExpired(ctx context.Context, userID int, key string, before time.Time) error {
+	return ikr.db.WithContext(ctx).Where("user_id = ? AND key = ? AND created_at < ?", userID, key, before).
+		Delete(&model.IdempotencyKey{}).Error
+}
+
+// DeleteAbandoned removes the key only while it is still in progress, so a response stored meanwhile is kept
+func (ikr *idempotencyKeyRepositoryImpl) DeleteAbandoned(ctx context.Context, userID int, key string, reservedBefore time.Time) error {
+	return ikr.db.WithContext(ctx).Where("user_id = ? AND key = ? AND status_code = 0 AND created_at < ?", userID, key, reservedBefore).
+		Delete(&model.IdempotencyKey{}).Error
+}
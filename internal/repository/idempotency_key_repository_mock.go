@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/stretchr/testify/mock"
+	"time"
+)
+
+type MockIdempotencyKeyRepository struct {
+	mock.Mock
+}
+
+func NewMockIdempotencyKeyRepository() *MockIdempotencyKeyRepository {
+	return &MockIdempotencyKeyRepository{}
+}
+
+func (mikr *MockIdempotencyKeyRepository) Reserve(ctx context.Context, record *model.IdempotencyKey) (bool, error) {
+	args := mikr.Called(record)
+	return args.Bool(0), args.Error(1)
+}
+
+func (mikr *MockIdempotencyKeyRepository) FindByKey(ctx context.Context, userID int, key string) (*model.IdempotencyKey, error) {
+	args := mikr.Called(userID, key)
+	return args.Get(0).(*model.IdempotencyKey), args.Error(1)
+}
+
+func (mikr *MockIdempotencyKeyRepository) Complete(ctx context.Context, userID int, key string, statusCode int, contentType string, body []byte) error {
+	args := mikr.Called(userID, key, statusCode, contentType, body)
+	return args.Error(0)
+}
+
+func (mikr *MockIdempotencyKeyRepository) Delete(ctx context.Context, userID int, key string) error {
+	args := mikr.Called(userID, key)
+	return args.Error(0)
+}
+
+func (mikr *MockIdempotencyKeyRepository) DeleteExpired(ctx context.Context, userID int, key string, before time.Time) error {
+	args := mikr.Called(userID, key, before)
+	return args.Error(0)
+}
+
+func (mikr *MockIdempotencyKeyRepository) DeleteAbandoned(ctx context.Context, userID int, key string, reservedBefore time.Time) error {
+	args := mikr.Called(userID, key, reservedBefore)
+	return args.Error(0)
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LedgerRepository interface {
+	Post(ctx context.Context, entry *model.JournalEntry) error
+	GetBalance(ctx context.Context, accountCode string) (int, error)
+	FindUsersWithoutAccount(ctx context.Context) ([]model.User, error)
+	FindMismatches(ctx context.Context) ([]model.BalanceMismatch, error)
+	GetUserHistory(ctx context.Context, userID int, filter model.HistoryFilter) ([]model.HistoryItem, error)
+	WithTx(tx *gorm.DB) LedgerRepository
+}
+
+type ledgerRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) LedgerRepository {
+	return &ledgerRepositoryImpl{db: db}
+}
+
+func (lr *ledgerRepositoryImpl) Post(ctx context.Context, entry *model.JournalEntry) error {
+	if !entry.Balanced() {
+		return enum.ErrUnbalancedEntry
+	}
+	if len(entry.Accounts) > 0 {
+		err := lr.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&entry.Accounts).Error
+		if err != nil {
+			return translateError(err)
+		}
+	}
+	return translateError(lr.db.WithContext(ctx).Create(entry).Error)
+}
+
+func (lr *ledgerRepositoryImpl) GetBalance(ctx context.Context, accountCode string) (int, error) {
+	var balance int
+	err := lr.db.WithContext(ctx).Model(&model.Posting{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("account_code = ?", accountCode).
+		Scan(&balance).Error
+	return balance, err
+}
+
+func (lr *ledgerRepositoryImpl) FindUsersWithoutAccount(ctx context.Context) ([]model.User, error) {
+	var users []model.User
+	err := lr.db.WithContext(ctx).Where("NOT EXISTS (SELECT 1 FROM ledger_accounts WHERE ledger_accounts.user_id = users.id)").
+		Order("id").
+		Find(&users).Error
+	return users, err
+}
+
+func (lr *ledgerRepositoryImpl) FindMismatches(ctx context.Context) ([]model.BalanceMismatch, error) {
+	var mismatches []model.BalanceMismatch
+	err := lr.db.WithContext(ctx).Table("users").
+		Select("users.id AS user_id, users.username, users.coins AS cached_coins, COALESCE(SUM(postings.amount), 0) AS ledger_coins").
+		Joins("LEFT JOIN ledger_accounts ON ledger_accounts.user_id = users.id").
+		Joins("LEFT JOIN postings ON postings.account_code = ledger_accounts.code").
+		Group("users.id, users.username, users.coins").
+		Having("users.coins <> COALESCE(SUM(postings.amount), 0)").
+		Order("users.id").
+		Scan(&mismatches).Error
+	return mismatches, err
+}
+
+// GetUserHistory lists the journal entries touching the user's account, newest first,
+// together with the other side of each entry
+func (lr *ledgerRepositoryImpl) GetUserHistory(ctx context.Context, userID int, filter model.HistoryFilter) ([]model.HistoryItem, error) {
+	query := lr.db.WithContext(ctx).Table("postings").
+		Select(`journal_entries.id, journal_entries.type, journal_entries.reference, journal_entries.created_at,
+			ABS(postings.amount) AS amount,
+			CASE WHEN postings.amount < 0 THEN ? ELSE ? END AS direction,
+			COALESCE(counterparties.username, '') AS counterparty,
+			COALESCE(purchases.merch_item, '') AS item,
+			COALESCE(purchases.quantity, 0) AS quantity,
+			COALESCE(purchases.unit_price, 0) AS unit_price,
+			COALESCE(purchases.total, 0) AS total,
+			COALESCE(purchases.price_estimated, false) AS price_estimated`, enum.DirectionOut, enum.DirectionIn).
+		Joins("JOIN journal_entries ON journal_entries.id = postings.journal_entry_id").
+		Joins("LEFT JOIN postings AS other_postings ON other_postings.journal_entry_id = postings.journal_entry_id AND other_postings.id <> postings.id").
+		Joins("LEFT JOIN ledger_accounts AS other_accounts ON other_accounts.code = other_postings.account_code").
+		Joins("LEFT JOIN users AS counterparties ON counterparties.id = other_accounts.user_id").
+		Joins("LEFT JOIN purchases ON journal_entries.type IN ? AND journal_entries.reference = CONCAT('purchase:', purchases.id)",
+			[]enum.EntryType{enum.EntryPurchase, enum.EntryRefund}).
+		Where("postings.account_code = ?", model.UserAccount(userID).Code)
+
+	switch filter.Direction {
+	case enum.DirectionIn:
+		query = query.Where("postings.amount >= 0")
+	case enum.DirectionOut:
+		query = query.Where("postings.amount < 0")
+	}
+	if filter.Counterparty != "" {
+		query = query.Where("counterparties.username = ?", filter.Counterparty)
+	}
+	if len(filter.Types) > 0 {
+		query = query.Where("journal_entries.type IN ?", filter.Types)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("journal_entries.created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("journal_entries.created_at < ?", filter.To)
+	}
+	if filter.Cursor != nil {
+		query = query.Where("(journal_entries.created_at, journal_entries.id) < (?, ?)", filter.Cursor.CreatedAt, filter.Cursor.ID)
+	}
+
+	var items []model.HistoryItem
+	err := query.Order("journal_entries.created_at DESC, journal_entries.id DESC").
+		Limit(filter.Limit).
+		Scan(&items).Error
+	return items, err
+}
+
+func (lr *ledgerRepositoryImpl) WithTx(tx *gorm.DB) LedgerRepository {
+	return &ledgerRepositoryImpl{db: tx}
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockLedgerRepository struct {
+	mock.Mock
+}
+
+func NewMockLedgerRepository() *MockLedgerRepository {
+	return &MockLedgerRepository{}
+}
+
+func (mlr *MockLedgerRepository) Post(ctx context.Context, entry *model.JournalEntry) error {
+	args := mlr.Called(entry)
+	return args.Error(0)
+}
+
+func (mlr *MockLedgerRepository) GetBalance(ctx context.Context, accountCode string) (int, error) {
+	args := mlr.Called(accountCode)
+	return args.Int(0), args.Error(1)
+}
+
+func (mlr *MockLedgerRepository) FindUsersWithoutAccount(ctx context.Context) ([]model.User, error) {
+	args := mlr.Called()
+	return args.Get(0).([]model.User), args.Error(1)
+}
+
+func (mlr *MockLedgerRepository) FindMismatches(ctx context.Context) ([]model.BalanceMismatch, error) {
+	args := mlr.Called()
+	return args.Get(0).([]model.BalanceMismatch), args.Error(1)
+}
+
+func (mlr *MockLedgerRepository) GetUserHistory(ctx context.Context, userID int, filter model.HistoryFilter) ([]model.HistoryItem, error) {
+	args := mlr.Called(userID, filter)
+	return args.Get(0).([]model.HistoryItem), args.Error(1)
+}
+
+func (mlr *MockLedgerRepository) WithTx(tx *gorm.DB) LedgerRepository {
+	return mlr
+}
@@ -1,13 +1,26 @@
 package repository
 
 import (
+	"context"
 	"github.com/ners1us/merch_store/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MerchRepository interface {
-	FindByName(name string) (*model.Merch, error)
-	InitializeMerch() error
+	FindByName(ctx context.Context, name string) (*model.Merch, error)
+	FindByNameUnscoped(ctx context.Context, name string) (*model.Merch, error)
+	FindByNameForUpdate(ctx context.Context, name string) (*model.Merch, error)
+	FindAll(ctx context.Context) ([]model.Merch, error)
+	FindAllUnscoped(ctx context.Context) ([]model.Merch, error)
+	Create(ctx context.Context, merch *model.Merch) error
+	Update(ctx context.Context, merch *model.Merch) error
+	UpdatePricing(ctx context.Context, name string, price int, stock *int) error
+	Retire(ctx context.Context, name string) error
+	DecrementStock(ctx context.Context, name string, quantity int) (bool, error)
+	IncrementStock(ctx context.Context, name string, quantity int) error
+	RunTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error
+	WithTx(tx *gorm.DB) MerchRepository
 }
 
 type merchRepositoryImpl struct {
@@ -18,23 +31,86 @@ func NewMerchRepository(db *gorm.DB) MerchRepository {
 	return &merchRepositoryImpl{db: db}
 }
 
-func (mr *merchRepositoryImpl) FindByName(name string) (*model.Merch, error) {
+func (mr *merchRepositoryImpl) FindByName(ctx context.Context, name string) (*model.Merch, error) {
 	var merch model.Merch
-	err := mr.db.Where("name = ?", name).First(&merch).Error
+	err := mr.db.WithContext(ctx).Where("name = ? AND retired = ?", name, false).First(&merch).Error
 	return &merch, err
 }
 
-func (mr *merchRepositoryImpl) InitializeMerch() error {
-	merch := []model.Merch{
-		{"t-shirt", 20},
-		{"cup", 20},
-		{"book", 50},
-		{"pen", 10},
-		{"powerbank", 200},
-		{"hoody", 300},
-		{"umbrella", 200},
-		{"socks", 10},
-		{"wallet", 50},
-		{"pink-hoody", 500}}
-	return mr.db.Create(&merch).Error
+func (mr *merchRepositoryImpl) FindByNameUnscoped(ctx context.Context, name string) (*model.Merch, error) {
+	var merch model.Merch
+	err := mr.db.WithContext(ctx).Where("name = ?", name).First(&merch).Error
+	return &merch, err
+}
+
+func (mr *merchRepositoryImpl) FindByNameForUpdate(ctx context.Context, name string) (*model.Merch, error) {
+	var merch model.Merch
+	err := mr.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("name = ? AND retired = ?", name, false).
+		First(&merch).Error
+	return &merch, err
+}
+
+func (mr *merchRepositoryImpl) FindAll(ctx context.Context) ([]model.Merch, error) {
+	var merch []model.Merch
+	err := mr.db.WithContext(ctx).Where("retired = ?", false).Order("name").Find(&merch).Error
+	return merch, err
+}
+
+func (mr *merchRepositoryImpl) FindAllUnscoped(ctx context.Context) ([]model.Merch, error) {
+	var merch []model.Merch
+	err := mr.db.WithContext(ctx).Order("name").Find(&merch).Error
+	return merch, err
+}
+
+func (mr *merchRepositoryImpl) Create(ctx context.Context, merch *model.Merch) error {
+	return translateError(mr.db.WithContext(ctx).Create(merch).Error)
+}
+
+func (mr *merchRepositoryImpl) Update(ctx context.Context, merch *model.Merch) error {
+	return translateError(mr.db.WithContext(ctx).Save(merch).Error)
+}
+
+// UpdatePricing sets the price and, when given, the stock, leaving the other columns to concurrent writers
+func (mr *merchRepositoryImpl) UpdatePricing(ctx context.Context, name string, price int, stock *int) error {
+	columns := map[string]interface{}{"price": price}
+	if stock != nil {
+		columns["stock"] = *stock
+	}
+	return translateError(mr.db.WithContext(ctx).Model(&model.Merch{}).Where("name = ?", name).UpdateColumns(columns).Error)
+}
+
+func (mr *merchRepositoryImpl) Retire(ctx context.Context, name string) error {
+	result := mr.db.WithContext(ctx).Model(&model.Merch{}).
+		Where("name = ? AND retired = ?", name, false).
+		Update("retired", true)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (mr *merchRepositoryImpl) DecrementStock(ctx context.Context, name string, quantity int) (bool, error) {
+	result := mr.db.WithContext(ctx).Model(&model.Merch{}).
+		Where("name = ? AND (stock IS NULL OR stock >= ?)", name, quantity).
+		Update("stock", gorm.Expr("stock - ?", quantity))
+	return result.RowsAffected == 1, result.Error
+}
+
+// IncrementStock returns units to stock, items without stock tracking are left untouched
+func (mr *merchRepositoryImpl) IncrementStock(ctx context.Context, name string, quantity int) error {
+	return mr.db.WithContext(ctx).Model(&model.Merch{}).
+		Where("name = ? AND stock IS NOT NULL", name).
+		Update("stock", gorm.Expr("stock + ?", quantity)).Error
+}
+
+func (mr *merchRepositoryImpl) RunTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return mr.db.WithContext(ctx).Transaction(fn)
+}
+
+func (mr *merchRepositoryImpl) WithTx(tx *gorm.DB) MerchRepository {
+	return &merchRepositoryImpl{db: tx}
 }
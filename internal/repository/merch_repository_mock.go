@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"context"
 	"github.com/ners1us/merch_store/internal/model"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 type MockMerchRepository struct {
@@ -13,12 +15,66 @@ func NewMockMerchRepository() *MockMerchRepository {
 	return &MockMerchRepository{}
 }
 
-func (mmr *MockMerchRepository) FindByName(name string) (*model.Merch, error) {
+func (mmr *MockMerchRepository) FindByName(ctx context.Context, name string) (*model.Merch, error) {
 	args := mmr.Called(name)
 	return args.Get(0).(*model.Merch), args.Error(1)
 }
 
-// InitializeMerch Not implemented
-func (mmr *MockMerchRepository) InitializeMerch() error {
-	return nil
+func (mmr *MockMerchRepository) FindByNameUnscoped(ctx context.Context, name string) (*model.Merch, error) {
+	args := mmr.Called(name)
+	return args.Get(0).(*model.Merch), args.Error(1)
+}
+
+func (mmr *MockMerchRepository) FindByNameForUpdate(ctx context.Context, name string) (*model.Merch, error) {
+	args := mmr.Called(name)
+	return args.Get(0).(*model.Merch), args.Error(1)
+}
+
+func (mmr *MockMerchRepository) FindAll(ctx context.Context) ([]model.Merch, error) {
+	args := mmr.Called()
+	return args.Get(0).([]model.Merch), args.Error(1)
+}
+
+func (mmr *MockMerchRepository) FindAllUnscoped(ctx context.Context) ([]model.Merch, error) {
+	args := mmr.Called()
+	return args.Get(0).([]model.Merch), args.Error(1)
+}
+
+func (mmr *MockMerchRepository) Create(ctx context.Context, merch *model.Merch) error {
+	args := mmr.Called(merch)
+	return args.Error(0)
+}
+
+func (mmr *MockMerchRepository) Update(ctx context.Context, merch *model.Merch) error {
+	args := mmr.Called(merch)
+	return args.Error(0)
+}
+
+func (mmr *MockMerchRepository) UpdatePricing(ctx context.Context, name string, price int, stock *int) error {
+	args := mmr.Called(name, price, stock)
+	return args.Error(0)
+}
+
+func (mmr *MockMerchRepository) Retire(ctx context.Context, name string) error {
+	args := mmr.Called(name)
+	return args.Error(0)
+}
+
+func (mmr *MockMerchRepository) DecrementStock(ctx context.Context, name string, quantity int) (bool, error) {
+	args := mmr.Called(name, quantity)
+	return args.Bool(0), args.Error(1)
+}
+
+func (mmr *MockMerchRepository) IncrementStock(ctx context.Context, name string, quantity int) error {
+	args := mmr.Called(name, quantity)
+	return args.Error(0)
+}
+
+func (mmr *MockMerchRepository) RunTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	args := mmr.Called(fn)
+	return args.Error(0)
+}
+
+func (mmr *MockMerchRepository) WithTx(tx *gorm.DB) MerchRepository {
+	return mmr
 }
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"github.com/ners1us/merch_store/internal/model"
+	"gorm.io/gorm"
+)
+
+type OrderRepository interface {
+	Create(ctx context.Context, order *model.Order) error
+	WithTx(tx *gorm.DB) OrderRepository
+}
+
+type orderRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewOrderRepository(db *gorm.DB) OrderRepository {
+	return &orderRepositoryImpl{db: db}
+}
+
+func (or *orderRepositoryImpl) Create(ctx context.Context, order *model.Order) error {
+	return translateError(or.db.WithContext(ctx).Create(order).Error)
+}
+
+func (or *orderRepositoryImpl) WithTx(tx *gorm.DB) OrderRepository {
+	return &orderRepositoryImpl{db: tx}
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockOrderRepository struct {
+	mock.Mock
+}
+
+func NewMockOrderRepository() *MockOrderRepository {
+	return &MockOrderRepository{}
+}
+
+func (mor *MockOrderRepository) Create(ctx context.Context, order *model.Order) error {
+	args := mor.Called(order)
+	return args.Error(0)
+}
+
+func (mor *MockOrderRepository) WithTx(tx *gorm.DB) OrderRepository {
+	return mor
+}
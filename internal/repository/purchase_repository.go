@@ -1,13 +1,18 @@
 package repository
 
 import (
+	"context"
 	"github.com/ners1us/merch_store/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PurchaseRepository interface {
-	Create(purchase *model.Purchase) error
-	GetUserPurchases(userID int) ([]model.InventoryItem, error)
+	Create(ctx context.Context, purchase *model.Purchase) error
+	FindByIDForUpdate(ctx context.Context, id int) (*model.Purchase, error)
+	Update(ctx context.Context, purchase *model.Purchase) error
+	GetUserPurchases(ctx context.Context, userID int) ([]model.InventoryItem, error)
+	WithTx(tx *gorm.DB) PurchaseRepository
 }
 
 type purchaseRepositoryImpl struct {
@@ -18,16 +23,30 @@ func NewPurchaseRepository(db *gorm.DB) PurchaseRepository {
 	return &purchaseRepositoryImpl{db: db}
 }
 
-func (pr *purchaseRepositoryImpl) Create(purchase *model.Purchase) error {
-	return pr.db.Create(purchase).Error
+func (pr *purchaseRepositoryImpl) Create(ctx context.Context, purchase *model.Purchase) error {
+	return translateError(pr.db.WithContext(ctx).Create(purchase).Error)
 }
 
-func (pr *purchaseRepositoryImpl) GetUserPurchases(userID int) ([]model.InventoryItem, error) {
+func (pr *purchaseRepositoryImpl) FindByIDForUpdate(ctx context.Context, id int) (*model.Purchase, error) {
+	var purchase model.Purchase
+	err := pr.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&purchase).Error
+	return &purchase, err
+}
+
+func (pr *purchaseRepositoryImpl) Update(ctx context.Context, purchase *model.Purchase) error {
+	return translateError(pr.db.WithContext(ctx).Save(purchase).Error)
+}
+
+func (pr *purchaseRepositoryImpl) GetUserPurchases(ctx context.Context, userID int) ([]model.InventoryItem, error) {
 	var inventory []model.InventoryItem
-	err := pr.db.Model(&model.Purchase{}).
-		Select("merch_item as type, count(*) as quantity").
-		Where("user_id = ?", userID).
+	err := pr.db.WithContext(ctx).Model(&model.Purchase{}).
+		Select("merch_item as type, sum(quantity) as quantity").
+		Where("user_id = ? AND refunded_at IS NULL", userID).
 		Group("merch_item").
 		Scan(&inventory).Error
 	return inventory, err
 }
+
+func (pr *purchaseRepositoryImpl) WithTx(tx *gorm.DB) PurchaseRepository {
+	return &purchaseRepositoryImpl{db: tx}
+}
@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"context"
 	"github.com/ners1us/merch_store/internal/model"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 type MockPurchaseRepository struct {
@@ -13,12 +15,26 @@ func NewMockPurchaseRepository() *MockPurchaseRepository {
 	return &MockPurchaseRepository{}
 }
 
-func (mpr *MockPurchaseRepository) Create(purchase *model.Purchase) error {
+func (mpr *MockPurchaseRepository) Create(ctx context.Context, purchase *model.Purchase) error {
 	args := mpr.Called(purchase)
 	return args.Error(0)
 }
 
-func (mpr *MockPurchaseRepository) GetUserPurchases(userID int) ([]model.InventoryItem, error) {
+func (mpr *MockPurchaseRepository) FindByIDForUpdate(ctx context.Context, id int) (*model.Purchase, error) {
+	args := mpr.Called(id)
+	return args.Get(0).(*model.Purchase), args.Error(1)
+}
+
+func (mpr *MockPurchaseRepository) Update(ctx context.Context, purchase *model.Purchase) error {
+	args := mpr.Called(purchase)
+	return args.Error(0)
+}
+
+func (mpr *MockPurchaseRepository) GetUserPurchases(ctx context.Context, userID int) ([]model.InventoryItem, error) {
 	args := mpr.Called(userID)
 	return args.Get(0).([]model.InventoryItem), args.Error(1)
 }
+
+func (mpr *MockPurchaseRepository) WithTx(tx *gorm.DB) PurchaseRepository {
+	return mpr
+}
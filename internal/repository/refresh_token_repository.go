@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"github.com/ners1us/merch_store/internal/model"
+	"gorm.io/gorm"
+	"time"
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *model.RefreshToken) error
+	FindByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error)
+	Revoke(ctx context.Context, id int) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+}
+
+type refreshTokenRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepositoryImpl{db: db}
+}
+
+func (rtr *refreshTokenRepositoryImpl) Create(ctx context.Context, token *model.RefreshToken) error {
+	return rtr.db.WithContext(ctx).Create(token).Error
+}
+
+func (rtr *refreshTokenRepositoryImpl) FindByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := rtr.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error
+	return &token, err
+}
+
+func (rtr *refreshTokenRepositoryImpl) Revoke(ctx context.Context, id int) (bool, error) {
+	result := rtr.db.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+func (rtr *refreshTokenRepositoryImpl) RevokeFamily(ctx context.Context, familyID string) error {
+	return rtr.db.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRefreshTokenRepository struct {
+	mock.Mock
+}
+
+func NewMockRefreshTokenRepository() *MockRefreshTokenRepository {
+	return &MockRefreshTokenRepository{}
+}
+
+func (mrtr *MockRefreshTokenRepository) Create(ctx context.Context, token *model.RefreshToken) error {
+	args := mrtr.Called(token)
+	return args.Error(0)
+}
+
+func (mrtr *MockRefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	args := mrtr.Called(tokenHash)
+	return args.Get(0).(*model.RefreshToken), args.Error(1)
+}
+
+func (mrtr *MockRefreshTokenRepository) Revoke(ctx context.Context, id int) (bool, error) {
+	args := mrtr.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (mrtr *MockRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	args := mrtr.Called(familyID)
+	return args.Error(0)
+}
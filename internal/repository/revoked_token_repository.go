@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"github.com/ners1us/merch_store/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type RevokedTokenRepository interface {
+	Create(ctx context.Context, token *model.RevokedToken) error
+	Exists(ctx context.Context, jti string) (bool, error)
+	DeleteExpired(ctx context.Context, before time.Time) error
+}
+
+type revokedTokenRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewRevokedTokenRepository(db *gorm.DB) RevokedTokenRepository {
+	return &revokedTokenRepositoryImpl{db: db}
+}
+
+func (rtr *revokedTokenRepositoryImpl) Create(ctx context.Context, token *model.RevokedToken) error {
+	return rtr.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+func (rtr *revokedTokenRepositoryImpl) Exists(ctx context.Context, jti string) (bool, error) {
+	var count int64
+	err := rtr.db.WithContext(ctx).Model(&model.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
+
+func (rtr *revokedTokenRepositoryImpl) DeleteExpired(ctx context.Context, before time.Time) error {
+	return rtr.db.WithContext(ctx).Where("expires_at < ?", before).Delete(&model.RevokedToken{}).Error
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/stretchr/testify/mock"
+	"time"
+)
+
+type MockRevokedTokenRepository struct {
+	mock.Mock
+}
+
+func NewMockRevokedTokenRepository() *MockRevokedTokenRepository {
+	return &MockRevokedTokenRepository{}
+}
+
+func (mrtr *MockRevokedTokenRepository) Create(ctx context.Context, token *model.RevokedToken) error {
+	args := mrtr.Called(token)
+	return args.Error(0)
+}
+
+func (mrtr *MockRevokedTokenRepository) Exists(ctx context.Context, jti string) (bool, error) {
+	args := mrtr.Called(jti)
+	return args.Bool(0), args.Error(1)
+}
+
+func (mrtr *MockRevokedTokenRepository) DeleteExpired(ctx context.Context, before time.Time) error {
+	args := mrtr.Called(before)
+	return args.Error(0)
+}
@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"context"
 	"github.com/ners1us/merch_store/internal/model"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 type MockCoinTransferRepository struct {
@@ -13,17 +15,21 @@ func NewMockCoinTransferRepository() *MockCoinTransferRepository {
 	return &MockCoinTransferRepository{}
 }
 
-func (mctr *MockCoinTransferRepository) Create(transfer *model.CoinTransfer) error {
+func (mctr *MockCoinTransferRepository) Create(ctx context.Context, transfer *model.CoinTransfer) error {
 	args := mctr.Called(transfer)
 	return args.Error(0)
 }
 
-func (mctr *MockCoinTransferRepository) GetReceivedTransfers(userID int) ([]model.ReceivedCoinHistory, error) {
-	args := mctr.Called(userID)
+func (mctr *MockCoinTransferRepository) GetReceivedTransfers(ctx context.Context, userID int, limit int) ([]model.ReceivedCoinHistory, error) {
+	args := mctr.Called(userID, limit)
 	return args.Get(0).([]model.ReceivedCoinHistory), args.Error(1)
 }
 
-func (mctr *MockCoinTransferRepository) GetSentTransfers(userID int) ([]model.SentCoinHistory, error) {
-	args := mctr.Called(userID)
+func (mctr *MockCoinTransferRepository) GetSentTransfers(ctx context.Context, userID int, limit int) ([]model.SentCoinHistory, error) {
+	args := mctr.Called(userID, limit)
 	return args.Get(0).([]model.SentCoinHistory), args.Error(1)
 }
+
+func (mctr *MockCoinTransferRepository) WithTx(tx *gorm.DB) CoinTransferRepository {
+	return mctr
+}
@@ -1,16 +1,23 @@
 package repository
 
 import (
+	"context"
+	"github.com/ners1us/merch_store/internal/enum"
 	"github.com/ners1us/merch_store/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
-	Create(user *model.User) error
-	FindByUsername(username string) (*model.User, error)
-	FindByID(id int) (*model.User, error)
-	Update(user *model.User) error
-	RunTransaction(fn func(tx *gorm.DB) error) error
+	Create(ctx context.Context, user *model.User) error
+	FindByUsername(ctx context.Context, username string) (*model.User, error)
+	FindByID(ctx context.Context, id int) (*model.User, error)
+	FindByIDForUpdate(ctx context.Context, id int) (*model.User, error)
+	Update(ctx context.Context, user *model.User) error
+	UpdateCoins(ctx context.Context, userID int, coins int) error
+	UpdateLocale(ctx context.Context, userID int, locale enum.Locale) error
+	RunTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error
+	WithTx(tx *gorm.DB) UserRepository
 }
 
 type userRepositoryImpl struct {
@@ -21,26 +28,53 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepositoryImpl{db: db}
 }
 
-func (ur *userRepositoryImpl) Create(user *model.User) error {
-	return ur.db.Create(user).Error
+func (ur *userRepositoryImpl) Create(ctx context.Context, user *model.User) error {
+	return translateError(ur.db.WithContext(ctx).Create(user).Error)
 }
 
-func (ur *userRepositoryImpl) FindByUsername(username string) (*model.User, error) {
+func (ur *userRepositoryImpl) FindByUsername(ctx context.Context, username string) (*model.User, error) {
 	var user model.User
-	err := ur.db.Where("username = ?", username).First(&user).Error
+	err := ur.db.WithContext(ctx).Where("username = ?", username).First(&user).Error
 	return &user, err
 }
 
-func (ur *userRepositoryImpl) FindByID(id int) (*model.User, error) {
+func (ur *userRepositoryImpl) FindByID(ctx context.Context, id int) (*model.User, error) {
 	var user model.User
-	err := ur.db.Where("id = ?", id).First(&user).Error
+	err := ur.db.WithContext(ctx).Where("id = ?", id).First(&user).Error
 	return &user, err
 }
 
-func (ur *userRepositoryImpl) Update(user *model.User) error {
-	return ur.db.Save(user).Error
+func (ur *userRepositoryImpl) FindByIDForUpdate(ctx context.Context, id int) (*model.User, error) {
+	var user model.User
+	err := ur.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&user).Error
+	return &user, err
+}
+
+// Update saves everything except the balance, which is a projection of the ledger kept by UpdateCoins
+func (ur *userRepositoryImpl) Update(ctx context.Context, user *model.User) error {
+	return translateError(ur.db.WithContext(ctx).Omit("coins").Save(user).Error)
+}
+
+func (ur *userRepositoryImpl) UpdateCoins(ctx context.Context, userID int, coins int) error {
+	return translateError(ur.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", userID).Update("coins", coins).Error)
+}
+
+// UpdateLocale writes only the locale column and reports a missing user as gorm.ErrRecordNotFound
+func (ur *userRepositoryImpl) UpdateLocale(ctx context.Context, userID int, locale enum.Locale) error {
+	result := ur.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", userID).UpdateColumn("locale", locale)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (ur *userRepositoryImpl) RunTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return ur.db.WithContext(ctx).Transaction(fn)
 }
 
-func (ur *userRepositoryImpl) RunTransaction(fn func(tx *gorm.DB) error) error {
-	return ur.db.Transaction(fn)
+func (ur *userRepositoryImpl) WithTx(tx *gorm.DB) UserRepository {
+	return &userRepositoryImpl{db: tx}
 }
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+	"github.com/ners1us/merch_store/internal/enum"
 	"github.com/ners1us/merch_store/internal/model"
 	"github.com/stretchr/testify/mock"
 	"gorm.io/gorm"
@@ -14,27 +16,48 @@ func NewMockUserRepository() *MockUserRepository {
 	return &MockUserRepository{}
 }
 
-func (mur *MockUserRepository) Create(user *model.User) error {
+func (mur *MockUserRepository) Create(ctx context.Context, user *model.User) error {
 	args := mur.Called(user)
 	return args.Error(0)
 }
 
-func (mur *MockUserRepository) FindByUsername(username string) (*model.User, error) {
+func (mur *MockUserRepository) FindByUsername(ctx context.Context, username string) (*model.User, error) {
 	args := mur.Called(username)
 	return args.Get(0).(*model.User), args.Error(1)
 }
 
-func (mur *MockUserRepository) FindByID(id int) (*model.User, error) {
+func (mur *MockUserRepository) FindByID(ctx context.Context, id int) (*model.User, error) {
 	args := mur.Called(id)
 	return args.Get(0).(*model.User), args.Error(1)
 }
 
-func (mur *MockUserRepository) Update(user *model.User) error {
+func (mur *MockUserRepository) FindByIDForUpdate(ctx context.Context, id int) (*model.User, error) {
+	args := mur.Called(id)
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (mur *MockUserRepository) Update(ctx context.Context, user *model.User) error {
 	args := mur.Called(user)
 	return args.Error(0)
 }
 
-func (mur *MockUserRepository) RunTransaction(fn func(tx *gorm.DB) error) error {
+func (mur *MockUserRepository) UpdateCoins(ctx context.Context, userID int, coins int) error {
+	args := mur.Called(userID, coins)
+	return args.Error(0)
+}
+
+func (mur *MockUserRepository) UpdateLocale(ctx context.Context, userID int, locale enum.Locale) error {
+	args := mur.Called(userID, locale)
+	return args.Error(0)
+}
+
+// RunTransaction does not open a transaction, tests call fn themselves and the WithTx methods of all repository mocks
+// return the mock they are called on
+func (mur *MockUserRepository) RunTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
 	args := mur.Called(fn)
 	return args.Error(0)
 }
+
+func (mur *MockUserRepository) WithTx(tx *gorm.DB) UserRepository {
+	return mur
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/repository"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+type AdjustmentService interface {
+	AdjustCoins(ctx context.Context, adminID int, username string, adjustmentType enum.AdjustmentType, req model.AdjustmentRequest) (*model.CoinAdjustment, error)
+}
+
+type adjustmentServiceImpl struct {
+	userRepo       repository.UserRepository
+	adjustmentRepo repository.CoinAdjustmentRepository
+	ledgerRepo     repository.LedgerRepository
+}
+
+func NewAdjustmentService(userRepo repository.UserRepository, adjustmentRepo repository.CoinAdjustmentRepository, ledgerRepo repository.LedgerRepository) AdjustmentService {
+	return &adjustmentServiceImpl{userRepo: userRepo, adjustmentRepo: adjustmentRepo, ledgerRepo: ledgerRepo}
+}
+
+func (as *adjustmentServiceImpl) AdjustCoins(ctx context.Context, adminID int, username string, adjustmentType enum.AdjustmentType, req model.AdjustmentRequest) (*model.CoinAdjustment, error) {
+	if req.Amount <= 0 {
+		return nil, enum.ErrCoinsInappropriateAmount
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, enum.ErrNoAdjustmentReason
+	}
+
+	var adjustment *model.CoinAdjustment
+	err := as.userRepo.RunTransaction(ctx, func(tx *gorm.DB) error {
+		userRepo := as.userRepo.WithTx(tx)
+		adjustmentRepo := as.adjustmentRepo.WithTx(tx)
+		ledgerRepo := as.ledgerRepo.WithTx(tx)
+
+		target, err := userRepo.FindByUsername(ctx, username)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return enum.ErrUserNotFound
+			}
+			return err
+		}
+		user, err := userRepo.FindByIDForUpdate(ctx, target.ID)
+		if err != nil {
+			return err
+		}
+
+		from, to := model.SystemAccount(model.AdjustmentAccountCode), model.UserAccount(user.ID)
+		if adjustmentType == enum.AdjustmentDebit {
+			if user.Coins < req.Amount {
+				return enum.ErrInsufficientMoney
+			}
+			from, to = to, from
+		}
+
+		adjustment = &model.CoinAdjustment{
+			UserID:    user.ID,
+			AdminID:   adminID,
+			Type:      adjustmentType,
+			Amount:    req.Amount,
+			Reason:    reason,
+			Reference: strings.TrimSpace(req.Reference),
+			CreatedAt: time.Now(),
+		}
+		if err := adjustmentRepo.Create(ctx, adjustment); err != nil {
+			return err
+		}
+
+		entry := model.NewJournalEntry(enum.EntryAdjustment, fmt.Sprintf("adjustment:%d", adjustment.ID), from, to, req.Amount)
+		return postEntry(ctx, userRepo, ledgerRepo, entry, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return adjustment, nil
+}
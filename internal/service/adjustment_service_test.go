@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestAdjustmentService_AdjustCoins(t *testing.T) {
+	// Arrange
+	mockUserRepo := repository.NewMockUserRepository()
+	mockAdjustmentRepo := repository.NewMockCoinAdjustmentRepository()
+	mockLedgerRepo := repository.NewMockLedgerRepository()
+	adjustmentService := NewAdjustmentService(mockUserRepo, mockAdjustmentRepo, mockLedgerRepo)
+
+	user := &model.User{ID: 2, Username: "bob", Coins: 100}
+	mockUserRepo.On("FindByUsername", "bob").Return(user, nil)
+	mockUserRepo.On("FindByIDForUpdate", 2).Return(user, nil)
+	mockAdjustmentRepo.On("Create", mock.Anything).Return(nil)
+	mockLedgerRepo.On("Post", mock.MatchedBy(func(entry *model.JournalEntry) bool {
+		return entry.Type == enum.EntryAdjustment && entry.Postings[0].AccountCode == model.AdjustmentAccountCode
+	})).Return(nil).Once()
+	mockLedgerRepo.On("GetBalance", "user:2").Return(150, nil).Once()
+	mockUserRepo.On("UpdateCoins", 2, 150).Return(nil).Once()
+	mockLedgerRepo.On("Post", mock.MatchedBy(func(entry *model.JournalEntry) bool {
+		return entry.Type == enum.EntryAdjustment && entry.Postings[1].AccountCode == model.AdjustmentAccountCode
+	})).Return(nil).Once()
+	mockLedgerRepo.On("GetBalance", "user:2").Return(120, nil).Once()
+	mockUserRepo.On("UpdateCoins", 2, 120).Return(nil).Once()
+	mockUserRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(0).(func(tx *gorm.DB) error)
+		assert.NoError(t, fn(nil))
+	}).Return(nil).Times(2)
+
+	// Act
+	adjustment, err := adjustmentService.AdjustCoins(context.Background(), 1, "bob", enum.AdjustmentCredit, model.AdjustmentRequest{
+		Amount:    50,
+		Reason:    " contest prize ",
+		Reference: "TICKET-1",
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 150, user.Coins)
+	assert.Equal(t, 1, adjustment.AdminID)
+	assert.Equal(t, 2, adjustment.UserID)
+	assert.Equal(t, enum.AdjustmentCredit, adjustment.Type)
+	assert.Equal(t, "contest prize", adjustment.Reason)
+	assert.Equal(t, "TICKET-1", adjustment.Reference)
+
+	// Act
+	adjustment, err = adjustmentService.AdjustCoins(context.Background(), 1, "bob", enum.AdjustmentDebit, model.AdjustmentRequest{Amount: 30, Reason: "correction"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 120, user.Coins)
+	assert.Equal(t, enum.AdjustmentDebit, adjustment.Type)
+
+	// Arrange
+	mockUserRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(0).(func(tx *gorm.DB) error)
+		assert.Equal(t, enum.ErrInsufficientMoney, fn(nil))
+	}).Return(enum.ErrInsufficientMoney).Once()
+
+	// Act
+	adjustment, err = adjustmentService.AdjustCoins(context.Background(), 1, "bob", enum.AdjustmentDebit, model.AdjustmentRequest{Amount: 500, Reason: "correction"})
+
+	// Assert
+	assert.Equal(t, enum.ErrInsufficientMoney, err)
+	assert.Nil(t, adjustment)
+	assert.Equal(t, 120, user.Coins)
+
+	// Arrange
+	mockUserRepo.On("FindByUsername", "ghost").Return(&model.User{}, gorm.ErrRecordNotFound)
+	mockUserRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(0).(func(tx *gorm.DB) error)
+		assert.Equal(t, enum.ErrUserNotFound, fn(nil))
+	}).Return(enum.ErrUserNotFound).Once()
+
+	// Act
+	_, err = adjustmentService.AdjustCoins(context.Background(), 1, "ghost", enum.AdjustmentCredit, model.AdjustmentRequest{Amount: 10, Reason: "bonus"})
+
+	// Assert
+	assert.Equal(t, enum.ErrUserNotFound, err)
+
+	// Act
+	_, err = adjustmentService.AdjustCoins(context.Background(), 1, "bob", enum.AdjustmentCredit, model.AdjustmentRequest{Amount: 10, Reason: "  "})
+
+	// Assert
+	assert.Equal(t, enum.ErrNoAdjustmentReason, err)
+
+	// Act
+	_, err = adjustmentService.AdjustCoins(context.Background(), 1, "bob", enum.AdjustmentCredit, model.AdjustmentRequest{Amount: 0, Reason: "bonus"})
+
+	// Assert
+	assert.Equal(t, enum.ErrCoinsInappropriateAmount, err)
+	mockAdjustmentRepo.AssertNumberOfCalls(t, "Create", 2)
+}
@@ -1,66 +1,201 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"github.com/ners1us/merch_store/internal/enum"
 	"github.com/ners1us/merch_store/internal/model"
 	"github.com/ners1us/merch_store/internal/repository"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
-	"time"
+	"regexp"
+	"slices"
+	"sync"
+	"unicode"
+)
 
-	"github.com/golang-jwt/jwt"
+const (
+	minPasswordLength = 8
+	signupBonus       = 1000
 )
 
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,32}$`)
+
+// dummyPasswordHash is compared against when the user does not exist, so a rejected login takes as long either way
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy_passw0rd"), bcrypt.DefaultCost)
+	return hash
+})
+
 type AuthService interface {
-	Authenticate(username, password string) (string, error)
+	Authenticate(ctx context.Context, username, password string) (*model.AuthResponse, error)
+	Register(ctx context.Context, username, password, inviteCode string) (*model.AuthResponse, error)
+	Refresh(ctx context.Context, refreshToken string) (*model.AuthResponse, error)
+	Logout(ctx context.Context, claims *model.Claims, refreshToken string) error
+	EnsureAdmin(ctx context.Context, username, password string) error
+}
+
+type RegistrationPolicy struct {
+	AutoRegister bool
+	InviteCodes  []string
+	AllowedUsers []string
 }
 
 type authServiceImpl struct {
-	userRepo  repository.UserRepository
-	jwtSecret []byte
+	userRepo     repository.UserRepository
+	ledgerRepo   repository.LedgerRepository
+	tokenService TokenService
+	policy       RegistrationPolicy
 }
 
-func NewAuthService(userRepo repository.UserRepository, jwtSecret []byte) AuthService {
-	return &authServiceImpl{userRepo: userRepo, jwtSecret: jwtSecret}
+func NewAuthService(userRepo repository.UserRepository, ledgerRepo repository.LedgerRepository, tokenService TokenService, policy RegistrationPolicy) AuthService {
+	return &authServiceImpl{userRepo: userRepo, ledgerRepo: ledgerRepo, tokenService: tokenService, policy: policy}
 }
 
-func (as *authServiceImpl) Authenticate(username, password string) (string, error) {
-	user, err := as.userRepo.FindByUsername(username)
+func (as *authServiceImpl) Authenticate(ctx context.Context, username, password string) (*model.AuthResponse, error) {
+	registered := false
+	user, err := as.userRepo.FindByUsername(ctx, username)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-			if err != nil {
-				return "", enum.ErrCreatingUser
-			}
-			user = &model.User{
-				Username: username,
-				Password: string(hash),
-				Coins:    1000,
-			}
-			if err := as.userRepo.Create(user); err != nil {
-				return "", enum.ErrCreatingUser
-			}
-		} else {
-			return "", enum.ErrInternalServer
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, enum.ErrInternalServer.Wrap(err)
+		}
+		if !as.policy.AutoRegister || !as.mayRegister(username, "") {
+			_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+			return nil, enum.ErrWrongCredentials
+		}
+		if err := validateCredentials(username, password); err != nil {
+			return nil, err
 		}
+		user, err = as.createUser(ctx, username, password)
+		if err != nil {
+			return nil, err
+		}
+		registered = true
 	} else {
 		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-			return "", enum.ErrWrongCredentials
+			return nil, enum.ErrWrongCredentials
 		}
 	}
 
-	claims := &model.Claims{
-		Username: user.Username,
-		UserID:   user.ID,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(time.Hour).Unix(),
-		},
+	return as.issueTokens(ctx, user, registered)
+}
+
+func (as *authServiceImpl) Register(ctx context.Context, username, password, inviteCode string) (*model.AuthResponse, error) {
+	if err := validateCredentials(username, password); err != nil {
+		return nil, err
+	}
+	if !as.mayRegister(username, inviteCode) {
+		return nil, enum.ErrRegistrationForbidden
+	}
+
+	_, err := as.userRepo.FindByUsername(ctx, username)
+	if err == nil {
+		return nil, enum.ErrUserAlreadyExists
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenStr, err := token.SignedString(as.jwtSecret)
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, enum.ErrInternalServer.Wrap(err)
+	}
+
+	user, err := as.createUser(ctx, username, password)
 	if err != nil {
-		return "", enum.ErrGeneratingToken
+		return nil, err
+	}
+	return as.issueTokens(ctx, user, true)
+}
+
+func (as *authServiceImpl) Refresh(ctx context.Context, refreshToken string) (*model.AuthResponse, error) {
+	return as.tokenService.Refresh(ctx, refreshToken)
+}
+
+func (as *authServiceImpl) Logout(ctx context.Context, claims *model.Claims, refreshToken string) error {
+	return as.tokenService.Revoke(ctx, claims, refreshToken)
+}
+
+// EnsureAdmin creates the admin account or promotes an existing one. An existing account is only promoted when its
+// password matches, since anyone could have registered the admin name by logging in with it first
+func (as *authServiceImpl) EnsureAdmin(ctx context.Context, username, password string) error {
+	if !isStrongPassword(password) {
+		return enum.ErrWeakPassword
+	}
+	user, err := as.userRepo.FindByUsername(ctx, username)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		user, err = as.createUser(ctx, username, password)
+		if err != nil {
+			return err
+		}
+	}
+	if user.Role == enum.RoleAdmin {
+		return nil
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return enum.ErrWrongCredentials
+	}
+	user.Role = enum.RoleAdmin
+	return as.userRepo.Update(ctx, user)
+}
+
+func (as *authServiceImpl) mayRegister(username, inviteCode string) bool {
+	if len(as.policy.InviteCodes) == 0 && len(as.policy.AllowedUsers) == 0 {
+		return true
+	}
+	if slices.Contains(as.policy.AllowedUsers, username) {
+		return true
+	}
+	return inviteCode != "" && slices.Contains(as.policy.InviteCodes, inviteCode)
+}
+
+func (as *authServiceImpl) issueTokens(ctx context.Context, user *model.User, registered bool) (*model.AuthResponse, error) {
+	response, err := as.tokenService.IssueTokens(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	response.Registered = registered
+	return response, nil
+}
+
+func (as *authServiceImpl) createUser(ctx context.Context, username, password string) (*model.User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, enum.ErrCreatingUser.Wrap(err)
+	}
+	user := &model.User{
+		Username: username,
+		Password: string(hash),
+		Coins:    signupBonus,
+		Role:     enum.RoleUser,
+	}
+	err = as.userRepo.RunTransaction(ctx, func(tx *gorm.DB) error {
+		if err := as.userRepo.WithTx(tx).Create(ctx, user); err != nil {
+			return err
+		}
+		entry := model.NewJournalEntry(enum.EntrySignupBonus, "", model.SystemAccount(model.IssuanceAccountCode), model.UserAccount(user.ID), signupBonus)
+		return as.ledgerRepo.WithTx(tx).Post(ctx, entry)
+	})
+	if err != nil {
+		return nil, enum.ErrCreatingUser.Wrap(err)
+	}
+	return user, nil
+}
+
+func isStrongPassword(password string) bool {
+	if len(password) < minPasswordLength {
+		return false
+	}
+	hasLetter := slices.ContainsFunc([]rune(password), unicode.IsLetter)
+	hasDigit := slices.ContainsFunc([]rune(password), unicode.IsDigit)
+	return hasLetter && hasDigit
+}
+
+// validateCredentials applies the username and password rules to every account a client signs up
+func validateCredentials(username, password string) error {
+	if !usernamePattern.MatchString(username) {
+		return enum.ErrInvalidUsername
+	}
+	if !isStrongPassword(password) {
+		return enum.ErrWeakPassword
 	}
-	return tokenStr, nil
+	return nil
 }
@@ -1,7 +1,9 @@
 package service
 
 import (
+	"context"
 	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/keys"
 	"github.com/ners1us/merch_store/internal/model"
 	"github.com/ners1us/merch_store/internal/repository"
 	"github.com/stretchr/testify/assert"
@@ -9,13 +11,20 @@ import (
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 	"testing"
+	"time"
 )
 
+func newTestTokenService(userRepo repository.UserRepository) TokenService {
+	mockRefreshTokenRepo := repository.NewMockRefreshTokenRepository()
+	mockRefreshTokenRepo.On("Create", mock.Anything).Return(nil)
+	return NewTokenService(userRepo, mockRefreshTokenRepo, repository.NewMockRevokedTokenRepository(), keys.NewHMACKeySet([]byte("secret")), time.Minute, time.Hour)
+}
+
 func TestAuthService_Authenticate(t *testing.T) {
 	// Arrange
 	mockUserRepo := repository.NewMockUserRepository()
-	jwtSecret := []byte("secret")
-	authService := NewAuthService(mockUserRepo, jwtSecret)
+	mockLedgerRepo := repository.NewMockLedgerRepository()
+	authService := NewAuthService(mockUserRepo, mockLedgerRepo, newTestTokenService(mockUserRepo), RegistrationPolicy{AutoRegister: true})
 
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("cool_password"), bcrypt.DefaultCost)
 	existingUser := &model.User{
@@ -26,31 +35,223 @@ func TestAuthService_Authenticate(t *testing.T) {
 	mockUserRepo.On("FindByUsername", "testuser").Return(existingUser, nil)
 
 	// Act
-	token, err := authService.Authenticate("testuser", "cool_password")
+	response, err := authService.Authenticate(context.Background(), "testuser", "cool_password")
 
 	// Assert
 	assert.NoError(t, err)
-	assert.NotEmpty(t, token)
+	assert.NotEmpty(t, response.Token)
+	assert.False(t, response.Registered)
 
 	// Arrange
 	mockUserRepo.On("FindByUsername", "newuser").Return(&model.User{}, gorm.ErrRecordNotFound)
 	mockUserRepo.On("Create", mock.Anything).Return(nil)
+	mockLedgerRepo.On("Post", mock.MatchedBy(func(entry *model.JournalEntry) bool {
+		return entry.Type == enum.EntrySignupBonus && entry.Balanced()
+	})).Return(nil)
+	mockUserRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(0).(func(tx *gorm.DB) error)
+		assert.NoError(t, fn(nil))
+	}).Return(nil)
 
 	// Act
-	token, err = authService.Authenticate("newuser", "new_password")
+	response, err = authService.Authenticate(context.Background(), "newuser", "new_passw0rd")
 
 	// Assert
 	assert.NoError(t, err)
-	assert.NotEmpty(t, token)
+	assert.NotEmpty(t, response.Token)
+	assert.True(t, response.Registered)
 
 	// Arrange
 	mockUserRepo.On("FindByUsername", "testuser").Return(existingUser, nil)
 
 	// Act
-	token, err = authService.Authenticate("testuser", "wrong_password")
+	response, err = authService.Authenticate(context.Background(), "testuser", "wrong_password")
 
 	// Assert
 	assert.Error(t, err)
 	assert.Equal(t, enum.ErrWrongCredentials, err)
-	assert.Empty(t, token)
+	assert.Nil(t, response)
+}
+
+func TestAuthService_AuthenticateWithoutAutoRegister(t *testing.T) {
+	// Arrange
+	mockUserRepo := repository.NewMockUserRepository()
+	mockLedgerRepo := repository.NewMockLedgerRepository()
+	authService := NewAuthService(mockUserRepo, mockLedgerRepo, newTestTokenService(mockUserRepo), RegistrationPolicy{AutoRegister: false})
+
+	mockUserRepo.On("FindByUsername", "typo").Return(&model.User{}, gorm.ErrRecordNotFound).Once()
+
+	// Act
+	response, err := authService.Authenticate(context.Background(), "typo", "cool_password")
+
+	// Assert
+	assert.Equal(t, enum.ErrWrongCredentials, err)
+	assert.Nil(t, response)
+	mockUserRepo.AssertNotCalled(t, "Create", mock.Anything)
+
+	// Act
+	cost, err := bcrypt.Cost(dummyPasswordHash())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, bcrypt.DefaultCost, cost)
+}
+
+func TestAuthService_Register(t *testing.T) {
+	// Arrange
+	mockUserRepo := repository.NewMockUserRepository()
+	mockLedgerRepo := repository.NewMockLedgerRepository()
+	authService := NewAuthService(mockUserRepo, mockLedgerRepo, newTestTokenService(mockUserRepo), RegistrationPolicy{
+		InviteCodes:  []string{"welcome-2025"},
+		AllowedUsers: []string{"ceo"},
+	})
+
+	mockUserRepo.On("FindByUsername", "newbie").Return(&model.User{}, gorm.ErrRecordNotFound).Once()
+	mockLedgerRepo.On("Post", mock.MatchedBy(func(entry *model.JournalEntry) bool {
+		return entry.Type == enum.EntrySignupBonus && entry.Balanced()
+	})).Return(nil)
+	mockUserRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(0).(func(tx *gorm.DB) error)
+		assert.NoError(t, fn(nil))
+	}).Return(nil)
+	mockUserRepo.On("Create", mock.Anything).Return(nil).Once()
+
+	// Act
+	response, err := authService.Register(context.Background(), "newbie", "passw0rdy", "welcome-2025")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotEmpty(t, response.Token)
+
+	// Arrange
+	mockUserRepo.On("FindByUsername", "ceo").Return(&model.User{}, gorm.ErrRecordNotFound).Once()
+	mockUserRepo.On("Create", mock.Anything).Return(nil).Once()
+
+	// Act
+	response, err = authService.Register(context.Background(), "ceo", "passw0rdy", "")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotEmpty(t, response.Token)
+
+	// Act
+	response, err = authService.Register(context.Background(), "stranger", "passw0rdy", "wrong-code")
+
+	// Assert
+	assert.Equal(t, enum.ErrRegistrationForbidden, err)
+	assert.Nil(t, response)
+
+	// Act
+	response, err = authService.Register(context.Background(), "newbie", "short1", "welcome-2025")
+
+	// Assert
+	assert.Equal(t, enum.ErrWeakPassword, err)
+	assert.Nil(t, response)
+
+	// Act
+	response, err = authService.Register(context.Background(), "no spaces allowed", "passw0rdy", "welcome-2025")
+
+	// Assert
+	assert.Equal(t, enum.ErrInvalidUsername, err)
+	assert.Nil(t, response)
+
+	// Arrange
+	mockUserRepo.On("FindByUsername", "oldtimer").Return(&model.User{ID: 7, Username: "oldtimer"}, nil).Once()
+
+	// Act
+	response, err = authService.Register(context.Background(), "oldtimer", "passw0rdy", "welcome-2025")
+
+	// Assert
+	assert.Equal(t, enum.ErrUserAlreadyExists, err)
+	assert.Nil(t, response)
+}
+
+func TestAuthService_EnsureAdmin(t *testing.T) {
+	// Arrange
+	mockUserRepo := repository.NewMockUserRepository()
+	mockLedgerRepo := repository.NewMockLedgerRepository()
+	authService := NewAuthService(mockUserRepo, mockLedgerRepo, newTestTokenService(mockUserRepo), RegistrationPolicy{})
+
+	mockUserRepo.On("FindByUsername", "root").Return(&model.User{}, gorm.ErrRecordNotFound).Once()
+	mockLedgerRepo.On("Post", mock.MatchedBy(func(entry *model.JournalEntry) bool {
+		return entry.Type == enum.EntrySignupBonus && entry.Balanced()
+	})).Return(nil)
+	mockUserRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(0).(func(tx *gorm.DB) error)
+		assert.NoError(t, fn(nil))
+	}).Return(nil)
+	mockUserRepo.On("Create", mock.Anything).Return(nil).Once()
+	mockUserRepo.On("Update", mock.MatchedBy(func(user *model.User) bool {
+		return user.Username == "root" && user.Role == enum.RoleAdmin
+	})).Return(nil).Once()
+
+	// Act
+	err := authService.EnsureAdmin(context.Background(), "root", "r00tpassword")
+
+	// Assert
+	assert.NoError(t, err)
+
+	// Arrange
+	mockUserRepo.On("FindByUsername", "chief").Return(&model.User{ID: 2, Username: "chief", Role: enum.RoleAdmin}, nil).Once()
+
+	// Act
+	err = authService.EnsureAdmin(context.Background(), "chief", "ch1efpassword")
+
+	// Assert
+	assert.NoError(t, err)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestAuthService_AuthenticateValidatesNewAccounts(t *testing.T) {
+	// Arrange
+	mockUserRepo := repository.NewMockUserRepository()
+	authService := NewAuthService(mockUserRepo, repository.NewMockLedgerRepository(), newTestTokenService(mockUserRepo), RegistrationPolicy{AutoRegister: true})
+
+	mockUserRepo.On("FindByUsername", mock.Anything).Return(&model.User{}, gorm.ErrRecordNotFound)
+
+	// Act
+	_, weakErr := authService.Authenticate(context.Background(), "newuser", "password")
+	_, usernameErr := authService.Authenticate(context.Background(), "x!", "new_passw0rd")
+
+	// Assert
+	assert.Equal(t, enum.ErrWeakPassword, weakErr)
+	assert.Equal(t, enum.ErrInvalidUsername, usernameErr)
+	mockUserRepo.AssertNotCalled(t, "RunTransaction", mock.Anything)
+}
+
+func TestAuthService_EnsureAdminRejectsWeakPassword(t *testing.T) {
+	// Arrange
+	mockUserRepo := repository.NewMockUserRepository()
+	authService := NewAuthService(mockUserRepo, repository.NewMockLedgerRepository(), newTestTokenService(mockUserRepo), RegistrationPolicy{})
+
+	// Act
+	emptyErr := authService.EnsureAdmin(context.Background(), "root", "")
+	weakErr := authService.EnsureAdmin(context.Background(), "root", "password")
+
+	// Assert
+	assert.Equal(t, enum.ErrWeakPassword, emptyErr)
+	assert.Equal(t, enum.ErrWeakPassword, weakErr)
+	mockUserRepo.AssertNotCalled(t, "FindByUsername", mock.Anything)
+}
+
+func TestAuthService_EnsureAdminPromotesExistingUser(t *testing.T) {
+	// Arrange
+	mockUserRepo := repository.NewMockUserRepository()
+	authService := NewAuthService(mockUserRepo, repository.NewMockLedgerRepository(), newTestTokenService(mockUserRepo), RegistrationPolicy{})
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("r00tpassword"), bcrypt.DefaultCost)
+	mockUserRepo.On("FindByUsername", "root").Return(&model.User{ID: 1, Username: "root", Password: string(hashedPassword), Role: enum.RoleUser}, nil).Once()
+	mockUserRepo.On("FindByUsername", "squatter").Return(&model.User{ID: 2, Username: "squatter", Password: string(hashedPassword), Role: enum.RoleUser}, nil).Once()
+	mockUserRepo.On("Update", mock.MatchedBy(func(user *model.User) bool {
+		return user.Username == "root" && user.Role == enum.RoleAdmin
+	})).Return(nil).Once()
+
+	// Act
+	promotedErr := authService.EnsureAdmin(context.Background(), "root", "r00tpassword")
+	squattedErr := authService.EnsureAdmin(context.Background(), "squatter", "an0therpassword")
+
+	// Assert
+	assert.NoError(t, promotedErr)
+	assert.Equal(t, enum.ErrWrongCredentials, squattedErr)
+	mockUserRepo.AssertExpectations(t)
 }
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/repository"
+	"gorm.io/gorm"
+)
+
+type CatalogService interface {
+	ListMerch(ctx context.Context) ([]model.Merch, error)
+	AddMerch(ctx context.Context, name string, price int, stock *int) (*model.Merch, error)
+	UpdateMerch(ctx context.Context, name string, price int, stock *int) (*model.Merch, error)
+	RetireMerch(ctx context.Context, name string) error
+	SeedCatalog(ctx context.Context, catalog []model.Merch, updatePrices, dryRun bool) ([]model.CatalogChange, error)
+}
+
+type catalogServiceImpl struct {
+	merchRepo repository.MerchRepository
+}
+
+func NewCatalogService(merchRepo repository.MerchRepository) CatalogService {
+	return &catalogServiceImpl{merchRepo: merchRepo}
+}
+
+func (cs *catalogServiceImpl) ListMerch(ctx context.Context) ([]model.Merch, error) {
+	merch, err := cs.merchRepo.FindAll(ctx)
+	if err != nil {
+		return nil, enum.ErrReceivingCatalog.Wrap(err)
+	}
+	return merch, nil
+}
+
+func (cs *catalogServiceImpl) AddMerch(ctx context.Context, name string, price int, stock *int) (*model.Merch, error) {
+	if name == "" {
+		return nil, enum.ErrNotProvidedItem
+	}
+	if err := validateMerch(price, stock); err != nil {
+		return nil, err
+	}
+
+	merch, err := cs.merchRepo.FindByNameUnscoped(ctx, name)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, enum.ErrInternalServer.Wrap(err)
+		}
+		merch = &model.Merch{Name: name, Price: price, Stock: stock}
+		if err := cs.merchRepo.Create(ctx, merch); err != nil {
+			return nil, enum.ErrInternalServer.Wrap(err)
+		}
+		return merch, nil
+	}
+
+	if !merch.Retired {
+		return nil, enum.ErrItemAlreadyExists
+	}
+	merch.Price = price
+	merch.Stock = stock
+	merch.Retired = false
+	if err := cs.merchRepo.Update(ctx, merch); err != nil {
+		return nil, enum.ErrInternalServer.Wrap(err)
+	}
+	return merch, nil
+}
+
+// UpdateMerch changes the price of an item and, when stock is given, its stock. The row is locked meanwhile,
+// so purchases decrementing the stock are neither lost nor overwritten
+func (cs *catalogServiceImpl) UpdateMerch(ctx context.Context, name string, price int, stock *int) (*model.Merch, error) {
+	if err := validateMerch(price, stock); err != nil {
+		return nil, err
+	}
+
+	var merch *model.Merch
+	err := cs.merchRepo.RunTransaction(ctx, func(tx *gorm.DB) error {
+		merchRepo := cs.merchRepo.WithTx(tx)
+
+		var err error
+		merch, err = merchRepo.FindByNameForUpdate(ctx, name)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return enum.ErrItemNotFound
+			}
+			return err
+		}
+		if err := merchRepo.UpdatePricing(ctx, name, price, stock); err != nil {
+			return err
+		}
+		merch.Price = price
+		if stock != nil {
+			merch.Stock = stock
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, enum.ErrInternalServer.Wrap(err)
+	}
+	return merch, nil
+}
+
+func (cs *catalogServiceImpl) RetireMerch(ctx context.Context, name string) error {
+	if err := cs.merchRepo.Retire(ctx, name); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return enum.ErrItemNotFound
+		}
+		return enum.ErrInternalServer.Wrap(err)
+	}
+	return nil
+}
+
+func (cs *catalogServiceImpl) SeedCatalog(ctx context.Context, catalog []model.Merch, updatePrices, dryRun bool) ([]model.CatalogChange, error) {
+	var changes []model.CatalogChange
+	err := cs.merchRepo.RunTransaction(ctx, func(tx *gorm.DB) error {
+		merchRepo := cs.merchRepo.WithTx(tx)
+
+		existing, err := merchRepo.FindAllUnscoped(ctx)
+		if err != nil {
+			return err
+		}
+		stored := make(map[string]model.Merch, len(existing))
+		for _, merch := range existing {
+			stored[merch.Name] = merch
+		}
+
+		for _, merch := range catalog {
+			current, found := stored[merch.Name]
+			change := model.CatalogChange{Name: merch.Name, OldPrice: current.Price, NewPrice: merch.Price}
+			switch {
+			case !found:
+				change.Action = enum.SeedCreate
+				if !dryRun {
+					if err := merchRepo.Create(ctx, &model.Merch{Name: merch.Name, Price: merch.Price, Stock: merch.Stock}); err != nil {
+						return err
+					}
+				}
+			case current.Retired:
+				change.Action = enum.SeedSkipRetired
+			case current.Price == merch.Price:
+				change.Action = enum.SeedUnchanged
+			case updatePrices:
+				change.Action = enum.SeedUpdatePrice
+				if !dryRun {
+					current.Price = merch.Price
+					if err := merchRepo.Update(ctx, &current); err != nil {
+						return err
+					}
+				}
+			default:
+				change.Action = enum.SeedKeepPrice
+			}
+			changes = append(changes, change)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func validateMerch(price int, stock *int) error {
+	if price <= 0 {
+		return enum.ErrInappropriatePrice
+	}
+	if stock != nil && *stock < 0 {
+		return enum.ErrInappropriateStock
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestCatalogService_ListMerch(t *testing.T) {
+	// Arrange
+	mockMerchRepo := repository.NewMockMerchRepository()
+	catalogService := NewCatalogService(mockMerchRepo)
+
+	catalog := []model.Merch{{Name: "cup", Price: 20}, {Name: "pen", Price: 10}}
+	mockMerchRepo.On("FindAll").Return(catalog, nil).Once()
+
+	// Act
+	merch, err := catalogService.ListMerch(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, catalog, merch)
+}
+
+func TestCatalogService_AddMerch(t *testing.T) {
+	// Arrange
+	mockMerchRepo := repository.NewMockMerchRepository()
+	catalogService := NewCatalogService(mockMerchRepo)
+
+	mockMerchRepo.On("FindByNameUnscoped", "mug").Return(&model.Merch{}, gorm.ErrRecordNotFound).Once()
+	mockMerchRepo.On("Create", mock.Anything).Return(nil).Once()
+
+	// Act
+	merch, err := catalogService.AddMerch(context.Background(), "mug", 30, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, &model.Merch{Name: "mug", Price: 30}, merch)
+
+	// Arrange
+	mockMerchRepo.On("FindByNameUnscoped", "cup").Return(&model.Merch{Name: "cup", Price: 20}, nil).Once()
+
+	// Act
+	merch, err = catalogService.AddMerch(context.Background(), "cup", 25, nil)
+
+	// Assert
+	assert.Equal(t, enum.ErrItemAlreadyExists, err)
+	assert.Nil(t, merch)
+
+	// Arrange
+	mockMerchRepo.On("FindByNameUnscoped", "umbrella").Return(&model.Merch{Name: "umbrella", Price: 200, Retired: true}, nil).Once()
+	mockMerchRepo.On("Update", mock.Anything).Return(nil).Once()
+
+	// Act
+	merch, err = catalogService.AddMerch(context.Background(), "umbrella", 150, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, &model.Merch{Name: "umbrella", Price: 150}, merch)
+
+	// Act
+	merch, err = catalogService.AddMerch(context.Background(), "free", 0, nil)
+
+	// Assert
+	assert.Equal(t, enum.ErrInappropriatePrice, err)
+	assert.Nil(t, merch)
+
+	// Arrange
+	negativeStock := -1
+
+	// Act
+	merch, err = catalogService.AddMerch(context.Background(), "ghost", 10, &negativeStock)
+
+	// Assert
+	assert.Equal(t, enum.ErrInappropriateStock, err)
+	assert.Nil(t, merch)
+}
+
+func TestCatalogService_UpdateMerch(t *testing.T) {
+	// Arrange
+	mockMerchRepo := repository.NewMockMerchRepository()
+	catalogService := NewCatalogService(mockMerchRepo)
+
+	stock := 50
+	mockMerchRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(0).(func(tx *gorm.DB) error)
+		_ = fn(nil)
+	}).Return(nil).Once()
+	mockMerchRepo.On("FindByNameForUpdate", "book").Return(&model.Merch{Name: "book", Price: 50}, nil).Once()
+	mockMerchRepo.On("UpdatePricing", "book", 70, &stock).Return(nil).Once()
+
+	// Act
+	merch, err := catalogService.UpdateMerch(context.Background(), "book", 70, &stock)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 70, merch.Price)
+	assert.Equal(t, &stock, merch.Stock)
+
+	// Arrange
+	mockMerchRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(0).(func(tx *gorm.DB) error)
+		assert.Equal(t, enum.ErrItemNotFound, fn(nil))
+	}).Return(enum.ErrItemNotFound).Once()
+	mockMerchRepo.On("FindByNameForUpdate", "candy").Return(&model.Merch{}, gorm.ErrRecordNotFound).Once()
+
+	// Act
+	merch, err = catalogService.UpdateMerch(context.Background(), "candy", 5, nil)
+
+	// Assert
+	assert.Equal(t, enum.ErrItemNotFound, err)
+	assert.Nil(t, merch)
+	mockMerchRepo.AssertExpectations(t)
+}
+
+func TestCatalogService_UpdateMerchKeepsStock(t *testing.T) {
+	// Arrange
+	mockMerchRepo := repository.NewMockMerchRepository()
+	catalogService := NewCatalogService(mockMerchRepo)
+
+	stock := 3
+	mockMerchRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(0).(func(tx *gorm.DB) error)
+		assert.NoError(t, fn(nil))
+	}).Return(nil).Once()
+	mockMerchRepo.On("FindByNameForUpdate", "hoody").Return(&model.Merch{Name: "hoody", Price: 300, Stock: &stock}, nil).Once()
+	mockMerchRepo.On("UpdatePricing", "hoody", 350, (*int)(nil)).Return(nil).Once()
+
+	// Act
+	merch, err := catalogService.UpdateMerch(context.Background(), "hoody", 350, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 350, merch.Price)
+	assert.Equal(t, 3, *merch.Stock)
+	mockMerchRepo.AssertExpectations(t)
+}
+
+func TestCatalogService_RetireMerch(t *testing.T) {
+	// Arrange
+	mockMerchRepo := repository.NewMockMerchRepository()
+	catalogService := NewCatalogService(mockMerchRepo)
+
+	mockMerchRepo.On("Retire", "socks").Return(nil).Once()
+	mockMerchRepo.On("Retire", "candy").Return(gorm.ErrRecordNotFound).Once()
+
+	// Act
+	err := catalogService.RetireMerch(context.Background(), "socks")
+
+	// Assert
+	assert.NoError(t, err)
+
+	// Act
+	err = catalogService.RetireMerch(context.Background(), "candy")
+
+	// Assert
+	assert.Equal(t, enum.ErrItemNotFound, err)
+}
+
+func TestCatalogService_SeedCatalog(t *testing.T) {
+	// Arrange
+	mockMerchRepo := repository.NewMockMerchRepository()
+	catalogService := NewCatalogService(mockMerchRepo)
+
+	existing := []model.Merch{
+		{Name: "t-shirt", Price: 20},
+		{Name: "cup", Price: 20},
+		{Name: "umbrella", Price: 200, Retired: true},
+	}
+	catalog := []model.Merch{
+		{Name: "t-shirt", Price: 80},
+		{Name: "cup", Price: 20},
+		{Name: "umbrella", Price: 250},
+		{Name: "pen", Price: 10},
+	}
+	mockMerchRepo.On("FindAllUnscoped").Return(existing, nil)
+	mockMerchRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(0).(func(tx *gorm.DB) error)
+		assert.NoError(t, fn(nil))
+	}).Return(nil)
+
+	// Act
+	changes, err := catalogService.SeedCatalog(context.Background(), catalog, false, true)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []model.CatalogChange{
+		{Name: "t-shirt", Action: enum.SeedKeepPrice, OldPrice: 20, NewPrice: 80},
+		{Name: "cup", Action: enum.SeedUnchanged, OldPrice: 20, NewPrice: 20},
+		{Name: "umbrella", Action: enum.SeedSkipRetired, OldPrice: 200, NewPrice: 250},
+		{Name: "pen", Action: enum.SeedCreate, OldPrice: 0, NewPrice: 10},
+	}, changes)
+	mockMerchRepo.AssertNotCalled(t, "Create", mock.Anything)
+
+	// Arrange
+	mockMerchRepo.On("Create", &model.Merch{Name: "pen", Price: 10}).Return(nil).Once()
+	mockMerchRepo.On("Update", &model.Merch{Name: "t-shirt", Price: 80}).Return(nil).Once()
+
+	// Act
+	changes, err = catalogService.SeedCatalog(context.Background(), catalog, true, false)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, enum.SeedUpdatePrice, changes[0].Action)
+	mockMerchRepo.AssertExpectations(t)
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/repository"
+	"strings"
+	"time"
+)
+
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+)
+
+var historyTypes = map[string][]enum.EntryType{
+	"transfer": {enum.EntryTransfer},
+	"purchase": {enum.EntryPurchase},
+	"grant":    {enum.EntrySignupBonus, enum.EntryOpeningBalance, enum.EntryAdjustment},
+	"refund":   {enum.EntryRefund},
+}
+
+type HistoryService interface {
+	GetHistory(ctx context.Context, userID int, req model.HistoryRequest) (*model.HistoryPage, error)
+}
+
+type historyServiceImpl struct {
+	ledgerRepo repository.LedgerRepository
+}
+
+func NewHistoryService(ledgerRepo repository.LedgerRepository) HistoryService {
+	return &historyServiceImpl{ledgerRepo: ledgerRepo}
+}
+
+func (hs *historyServiceImpl) GetHistory(ctx context.Context, userID int, req model.HistoryRequest) (*model.HistoryPage, error) {
+	filter, err := newHistoryFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// One extra row tells whether another page follows
+	limit := filter.Limit
+	filter.Limit++
+	items, err := hs.ledgerRepo.GetUserHistory(ctx, userID, filter)
+	if err != nil {
+		return nil, enum.ErrReceivingHistory.Wrap(err)
+	}
+
+	page := &model.HistoryPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = encodeCursor(model.HistoryCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	if page.Items == nil {
+		page.Items = []model.HistoryItem{}
+	}
+	return page, nil
+}
+
+func newHistoryFilter(req model.HistoryRequest) (model.HistoryFilter, error) {
+	filter := model.HistoryFilter{
+		Counterparty: req.Counterparty,
+		From:         req.From,
+		To:           req.To,
+		Limit:        req.Limit,
+	}
+
+	switch direction := enum.Direction(req.Direction); direction {
+	case "", enum.DirectionIn, enum.DirectionOut:
+		filter.Direction = direction
+	default:
+		return filter, enum.ErrInvalidHistoryFilter
+	}
+
+	if req.Type != "" {
+		for _, name := range strings.Split(req.Type, ",") {
+			types, ok := historyTypes[strings.TrimSpace(name)]
+			if !ok {
+				return filter, enum.ErrInvalidHistoryFilter
+			}
+			filter.Types = append(filter.Types, types...)
+		}
+	}
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return filter, enum.ErrInvalidHistoryFilter
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultHistoryLimit
+	}
+	if filter.Limit < 0 || filter.Limit > maxHistoryLimit {
+		return filter, enum.ErrInappropriateLimit
+	}
+
+	if req.Cursor != "" {
+		cursor, err := decodeCursor(req.Cursor)
+		if err != nil {
+			return filter, enum.ErrInvalidCursor
+		}
+		filter.Cursor = cursor
+	}
+	return filter, nil
+}
+
+func encodeCursor(cursor model.HistoryCursor) string {
+	raw := fmt.Sprintf("%d:%d", cursor.CreatedAt.UnixNano(), cursor.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(encoded string) (*model.HistoryCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	var nanos int64
+	var id int
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &nanos, &id); err != nil {
+		return nil, err
+	}
+	return &model.HistoryCursor{CreatedAt: time.Unix(0, nanos), ID: id}, nil
+}
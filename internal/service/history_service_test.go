@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestHistoryService_GetHistory(t *testing.T) {
+	// Arrange
+	mockLedgerRepo := repository.NewMockLedgerRepository()
+	historyService := NewHistoryService(mockLedgerRepo)
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	items := []model.HistoryItem{
+		{ID: 9, Type: enum.EntryTransfer, Direction: enum.DirectionOut, Amount: 10, Counterparty: "bob", CreatedAt: now},
+		{ID: 7, Type: enum.EntryPurchase, Direction: enum.DirectionOut, Amount: 80, Item: "t-shirt", CreatedAt: now.Add(-time.Minute)},
+		{ID: 4, Type: enum.EntryTransfer, Direction: enum.DirectionIn, Amount: 5, Counterparty: "bob", CreatedAt: now.Add(-time.Hour)},
+	}
+	mockLedgerRepo.On("GetUserHistory", 1, mock.MatchedBy(func(filter model.HistoryFilter) bool {
+		return filter.Limit == 3 && filter.Cursor == nil && filter.Direction == ""
+	})).Return(items, nil).Once()
+
+	// Act
+	page, err := historyService.GetHistory(context.Background(), 1, model.HistoryRequest{Limit: 2})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, items[:2], page.Items)
+	assert.NotEmpty(t, page.NextCursor)
+
+	// Arrange
+	mockLedgerRepo.On("GetUserHistory", 1, mock.MatchedBy(func(filter model.HistoryFilter) bool {
+		return filter.Cursor != nil && filter.Cursor.ID == 7 && filter.Cursor.CreatedAt.Equal(now.Add(-time.Minute)) &&
+			filter.Direction == enum.DirectionIn && filter.Counterparty == "bob" &&
+			assert.ObjectsAreEqual([]enum.EntryType{enum.EntryTransfer, enum.EntrySignupBonus, enum.EntryOpeningBalance, enum.EntryAdjustment}, filter.Types)
+	})).Return(items[2:], nil).Once()
+
+	// Act
+	page, err = historyService.GetHistory(context.Background(), 1, model.HistoryRequest{
+		Limit:        2,
+		Cursor:       page.NextCursor,
+		Direction:    "in",
+		Counterparty: "bob",
+		Type:         "transfer,grant",
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, items[2:], page.Items)
+	assert.Empty(t, page.NextCursor)
+	mockLedgerRepo.AssertExpectations(t)
+}
+
+func TestHistoryService_GetHistoryValidation(t *testing.T) {
+	// Arrange
+	historyService := NewHistoryService(repository.NewMockLedgerRepository())
+	now := time.Now()
+
+	for _, tc := range []struct {
+		req      model.HistoryRequest
+		expected error
+	}{
+		{model.HistoryRequest{Direction: "sideways"}, enum.ErrInvalidHistoryFilter},
+		{model.HistoryRequest{Type: "transfer,lottery"}, enum.ErrInvalidHistoryFilter},
+		{model.HistoryRequest{From: now, To: now.Add(-time.Hour)}, enum.ErrInvalidHistoryFilter},
+		{model.HistoryRequest{Limit: maxHistoryLimit + 1}, enum.ErrInappropriateLimit},
+		{model.HistoryRequest{Limit: -1}, enum.ErrInappropriateLimit},
+		{model.HistoryRequest{Cursor: "not a cursor!"}, enum.ErrInvalidCursor},
+	} {
+		// Act
+		page, err := historyService.GetHistory(context.Background(), 1, tc.req)
+
+		// Assert
+		assert.Equal(t, tc.expected, err)
+		assert.Nil(t, page)
+	}
+}
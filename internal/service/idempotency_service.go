@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/repository"
+	"gorm.io/gorm"
+	"time"
+)
+
+type IdempotencyService interface {
+	// Begin reserves the key for a new request and returns nil, or returns the stored response of a finished one
+	Begin(ctx context.Context, userID int, key, requestHash string) (*model.IdempotencyKey, error)
+	Complete(ctx context.Context, userID int, key string, statusCode int, contentType string, body []byte) error
+	Release(ctx context.Context, userID int, key string) error
+}
+
+type idempotencyServiceImpl struct {
+	idempotencyRepo repository.IdempotencyKeyRepository
+	ttl             time.Duration
+	lease           time.Duration
+}
+
+// NewIdempotencyService keeps responses for ttl. A key still in progress after lease belongs to a request that died
+// without releasing it, so lease should exceed the longest a request may run
+func NewIdempotencyService(idempotencyRepo repository.IdempotencyKeyRepository, ttl, lease time.Duration) IdempotencyService {
+	return &idempotencyServiceImpl{idempotencyRepo: idempotencyRepo, ttl: ttl, lease: lease}
+}
+
+func (is *idempotencyServiceImpl) Begin(ctx context.Context, userID int, key, requestHash string) (*model.IdempotencyKey, error) {
+	if key == "" || len(key) > 255 {
+		return nil, enum.ErrWrongIdempotencyKey
+	}
+
+	record := &model.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   time.Now(),
+	}
+	reserved, err := is.idempotencyRepo.Reserve(ctx, record)
+	if err != nil {
+		return nil, enum.ErrInternalServer.Wrap(err)
+	}
+	if reserved {
+		return nil, nil
+	}
+
+	stored, err := is.idempotencyRepo.FindByKey(ctx, userID, key)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, enum.ErrIdempotencyKeyInProgress
+		}
+		return nil, enum.ErrInternalServer.Wrap(err)
+	}
+
+	if time.Since(stored.CreatedAt) > is.ttl {
+		if err := is.idempotencyRepo.DeleteExpired(ctx, userID, key, time.Now().Add(-is.ttl)); err != nil {
+			return nil, enum.ErrInternalServer.Wrap(err)
+		}
+		return nil, is.reserveAgain(ctx, record)
+	}
+	// CreatedAt is the reservation time, the request holding the key can no longer be running after the lease
+	if stored.StatusCode == 0 && time.Since(stored.CreatedAt) > is.lease {
+		if err := is.idempotencyRepo.DeleteAbandoned(ctx, userID, key, time.Now().Add(-is.lease)); err != nil {
+			return nil, enum.ErrInternalServer.Wrap(err)
+		}
+		return nil, is.reserveAgain(ctx, record)
+	}
+
+	if stored.RequestHash != requestHash {
+		return nil, enum.ErrIdempotencyKeyReused
+	}
+	if stored.StatusCode == 0 {
+		return nil, enum.ErrIdempotencyKeyInProgress
+	}
+	return stored, nil
+}
+
+// reserveAgain takes over a key freed by Begin, another request may have reserved it first
+func (is *idempotencyServiceImpl) reserveAgain(ctx context.Context, record *model.IdempotencyKey) error {
+	reserved, err := is.idempotencyRepo.Reserve(ctx, record)
+	if err != nil {
+		return enum.ErrInternalServer.Wrap(err)
+	}
+	if !reserved {
+		return enum.ErrIdempotencyKeyInProgress
+	}
+	return nil
+}
+
+func (is *idempotencyServiceImpl) Complete(ctx context.Context, userID int, key string, statusCode int, contentType string, body []byte) error {
+	return is.idempotencyRepo.Complete(ctx, userID, key, statusCode, contentType, body)
+}
+
+func (is *idempotencyServiceImpl) Release(ctx context.Context, userID int, key string) error {
+	return is.idempotencyRepo.Delete(ctx, userID, key)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyService_Begin(t *testing.T) {
+	// Arrange
+	mockIdempotencyRepo := repository.NewMockIdempotencyKeyRepository()
+	idempotencyService := NewIdempotencyService(mockIdempotencyRepo, time.Hour, time.Minute)
+
+	mockIdempotencyRepo.On("Reserve", mock.Anything).Return(true, nil).Once()
+
+	// Act
+	stored, err := idempotencyService.Begin(context.Background(), 1, "new-key", "hash")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+
+	// Arrange
+	completed := &model.IdempotencyKey{
+		UserID:       1,
+		Key:          "done-key",
+		RequestHash:  "hash",
+		StatusCode:   200,
+		ResponseBody: []byte(`{"message":"ok"}`),
+		CreatedAt:    time.Now(),
+	}
+	mockIdempotencyRepo.On("Reserve", mock.Anything).Return(false, nil).Once()
+	mockIdempotencyRepo.On("FindByKey", 1, "done-key").Return(completed, nil).Once()
+
+	// Act
+	stored, err = idempotencyService.Begin(context.Background(), 1, "done-key", "hash")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, completed, stored)
+
+	// Arrange
+	mockIdempotencyRepo.On("Reserve", mock.Anything).Return(false, nil).Once()
+	mockIdempotencyRepo.On("FindByKey", 1, "done-key").Return(completed, nil).Once()
+
+	// Act
+	stored, err = idempotencyService.Begin(context.Background(), 1, "done-key", "other-hash")
+
+	// Assert
+	assert.Equal(t, enum.ErrIdempotencyKeyReused, err)
+	assert.Nil(t, stored)
+
+	// Arrange
+	inFlight := &model.IdempotencyKey{UserID: 1, Key: "busy-key", RequestHash: "hash", CreatedAt: time.Now()}
+	mockIdempotencyRepo.On("Reserve", mock.Anything).Return(false, nil).Once()
+	mockIdempotencyRepo.On("FindByKey", 1, "busy-key").Return(inFlight, nil).Once()
+
+	// Act
+	stored, err = idempotencyService.Begin(context.Background(), 1, "busy-key", "hash")
+
+	// Assert
+	assert.Equal(t, enum.ErrIdempotencyKeyInProgress, err)
+	assert.Nil(t, stored)
+
+	// Arrange
+	expired := &model.IdempotencyKey{UserID: 1, Key: "old-key", RequestHash: "hash", StatusCode: 200, CreatedAt: time.Now().Add(-2 * time.Hour)}
+	mockIdempotencyRepo.On("Reserve", mock.Anything).Return(false, nil).Once()
+	mockIdempotencyRepo.On("FindByKey", 1, "old-key").Return(expired, nil).Once()
+	mockIdempotencyRepo.On("DeleteExpired", 1, "old-key", mock.Anything).Return(nil).Once()
+	mockIdempotencyRepo.On("Reserve", mock.Anything).Return(true, nil).Once()
+
+	// Act
+	stored, err = idempotencyService.Begin(context.Background(), 1, "old-key", "hash")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+
+	// Arrange
+	abandoned := &model.IdempotencyKey{UserID: 1, Key: "crashed-key", RequestHash: "other-hash", CreatedAt: time.Now().Add(-2 * time.Minute)}
+	mockIdempotencyRepo.On("Reserve", mock.Anything).Return(false, nil).Once()
+	mockIdempotencyRepo.On("FindByKey", 1, "crashed-key").Return(abandoned, nil).Once()
+	mockIdempotencyRepo.On("DeleteAbandoned", 1, "crashed-key", mock.Anything).Return(nil).Once()
+	mockIdempotencyRepo.On("Reserve", mock.Anything).Return(true, nil).Once()
+
+	// Act
+	stored, err = idempotencyService.Begin(context.Background(), 1, "crashed-key", "hash")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+
+	// Act
+	stored, err = idempotencyService.Begin(context.Background(), 1, "", "hash")
+
+	// Assert
+	assert.Equal(t, enum.ErrWrongIdempotencyKey, err)
+	assert.Nil(t, stored)
+	mockIdempotencyRepo.AssertExpectations(t)
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/repository"
+	"gorm.io/gorm"
+)
+
+type LedgerService interface {
+	OpenBalances(ctx context.Context) (int, error)
+	Reconcile(ctx context.Context) ([]model.BalanceMismatch, error)
+}
+
+type ledgerServiceImpl struct {
+	userRepo   repository.UserRepository
+	ledgerRepo repository.LedgerRepository
+}
+
+func NewLedgerService(userRepo repository.UserRepository, ledgerRepo repository.LedgerRepository) LedgerService {
+	return &ledgerServiceImpl{userRepo: userRepo, ledgerRepo: ledgerRepo}
+}
+
+// OpenBalances posts an opening entry for every user created before the ledger existed,
+// so their cached balance becomes part of the ledger history
+func (ls *ledgerServiceImpl) OpenBalances(ctx context.Context) (int, error) {
+	opened := 0
+	err := ls.userRepo.RunTransaction(ctx, func(tx *gorm.DB) error {
+		userRepo := ls.userRepo.WithTx(tx)
+		ledgerRepo := ls.ledgerRepo.WithTx(tx)
+
+		users, err := ledgerRepo.FindUsersWithoutAccount(ctx)
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			locked, err := userRepo.FindByIDForUpdate(ctx, user.ID)
+			if err != nil {
+				return err
+			}
+			entry := model.NewJournalEntry(enum.EntryOpeningBalance, "", model.SystemAccount(model.IssuanceAccountCode), model.UserAccount(locked.ID), locked.Coins)
+			if err := ledgerRepo.Post(ctx, entry); err != nil {
+				return err
+			}
+			opened++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return opened, nil
+}
+
+func (ls *ledgerServiceImpl) Reconcile(ctx context.Context) ([]model.BalanceMismatch, error) {
+	return ls.ledgerRepo.FindMismatches(ctx)
+}
+
+// postEntry appends the entry to the ledger and refreshes the cached balances of the given users from it
+func postEntry(ctx context.Context, userRepo repository.UserRepository, ledgerRepo repository.LedgerRepository, entry *model.JournalEntry, users ...*model.User) error {
+	if err := ledgerRepo.Post(ctx, entry); err != nil {
+		return err
+	}
+	return refreshBalances(ctx, userRepo, ledgerRepo, users...)
+}
+
+func refreshBalances(ctx context.Context, userRepo repository.UserRepository, ledgerRepo repository.LedgerRepository, users ...*model.User) error {
+	for _, user := range users {
+		balance, err := ledgerRepo.GetBalance(ctx, model.UserAccount(user.ID).Code)
+		if err != nil {
+			return err
+		}
+		if err := userRepo.UpdateCoins(ctx, user.ID, balance); err != nil {
+			return err
+		}
+		user.Coins = balance
+	}
+	return nil
+}
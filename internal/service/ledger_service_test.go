@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestLedgerService_OpenBalances(t *testing.T) {
+	// Arrange
+	mockUserRepo := repository.NewMockUserRepository()
+	mockLedgerRepo := repository.NewMockLedgerRepository()
+	ledgerService := NewLedgerService(mockUserRepo, mockLedgerRepo)
+
+	mockLedgerRepo.On("FindUsersWithoutAccount").Return([]model.User{{ID: 3}, {ID: 5}}, nil).Once()
+	mockUserRepo.On("FindByIDForUpdate", 3).Return(&model.User{ID: 3, Coins: 700}, nil).Once()
+	mockUserRepo.On("FindByIDForUpdate", 5).Return(&model.User{ID: 5, Coins: 0}, nil).Once()
+	mockLedgerRepo.On("Post", mock.MatchedBy(func(entry *model.JournalEntry) bool {
+		return entry.Type == enum.EntryOpeningBalance && entry.Balanced() &&
+			entry.Postings[0].AccountCode == model.IssuanceAccountCode &&
+			entry.Postings[1].AccountCode == "user:3" && entry.Postings[1].Amount == 700
+	})).Return(nil).Once()
+	mockLedgerRepo.On("Post", mock.MatchedBy(func(entry *model.JournalEntry) bool {
+		return entry.Postings[1].AccountCode == "user:5" && entry.Postings[1].Amount == 0
+	})).Return(nil).Once()
+	mockUserRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(0).(func(tx *gorm.DB) error)
+		assert.NoError(t, fn(nil))
+	}).Return(nil).Once()
+
+	// Act
+	opened, err := ledgerService.OpenBalances(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, opened)
+	mockLedgerRepo.AssertExpectations(t)
+}
+
+func TestLedgerService_Reconcile(t *testing.T) {
+	// Arrange
+	mockUserRepo := repository.NewMockUserRepository()
+	mockLedgerRepo := repository.NewMockLedgerRepository()
+	ledgerService := NewLedgerService(mockUserRepo, mockLedgerRepo)
+
+	mismatches := []model.BalanceMismatch{{UserID: 1, Username: "alice", CachedCoins: 1200, LedgerCoins: 1000}}
+	mockLedgerRepo.On("FindMismatches").Return(mismatches, nil).Once()
+
+	// Act
+	result, err := ledgerService.Reconcile(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, mismatches, result)
+}
+
+func TestJournalEntry_Balanced(t *testing.T) {
+	// Arrange
+	entry := model.NewJournalEntry(enum.EntryTransfer, "", model.UserAccount(1), model.UserAccount(2), 50)
+	unbalanced := &model.JournalEntry{Postings: []model.Posting{{AccountCode: "user:1", Amount: -50}, {AccountCode: "user:2", Amount: 40}}}
+
+	// Act
+	balanced, skewed, empty := entry.Balanced(), unbalanced.Balanced(), (&model.JournalEntry{}).Balanced()
+
+	// Assert
+	assert.True(t, balanced)
+	assert.False(t, skewed)
+	assert.False(t, empty)
+}
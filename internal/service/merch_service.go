@@ -1,7 +1,9 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"github.com/ners1us/merch_store/internal/enum"
 	"github.com/ners1us/merch_store/internal/model"
 	"github.com/ners1us/merch_store/internal/repository"
@@ -10,22 +12,29 @@ import (
 )
 
 type MerchService interface {
-	BuyMerch(userID int, item string) error
+	BuyMerch(ctx context.Context, userID int, item string) (*model.Purchase, error)
 }
 
 type merchServiceImpl struct {
 	userRepo     repository.UserRepository
 	merchRepo    repository.MerchRepository
 	purchaseRepo repository.PurchaseRepository
+	ledgerRepo   repository.LedgerRepository
 }
 
-func NewMerchService(userRepo repository.UserRepository, merchRepo repository.MerchRepository, purchaseRepo repository.PurchaseRepository) MerchService {
-	return &merchServiceImpl{userRepo: userRepo, merchRepo: merchRepo, purchaseRepo: purchaseRepo}
+func NewMerchService(userRepo repository.UserRepository, merchRepo repository.MerchRepository, purchaseRepo repository.PurchaseRepository, ledgerRepo repository.LedgerRepository) MerchService {
+	return &merchServiceImpl{userRepo: userRepo, merchRepo: merchRepo, purchaseRepo: purchaseRepo, ledgerRepo: ledgerRepo}
 }
 
-func (ms *merchServiceImpl) BuyMerch(userID int, item string) error {
-	return ms.userRepo.RunTransaction(func(tx *gorm.DB) error {
-		merch, err := ms.merchRepo.FindByName(item)
+func (ms *merchServiceImpl) BuyMerch(ctx context.Context, userID int, item string) (*model.Purchase, error) {
+	var purchase *model.Purchase
+	err := ms.userRepo.RunTransaction(ctx, func(tx *gorm.DB) error {
+		userRepo := ms.userRepo.WithTx(tx)
+		merchRepo := ms.merchRepo.WithTx(tx)
+		purchaseRepo := ms.purchaseRepo.WithTx(tx)
+		ledgerRepo := ms.ledgerRepo.WithTx(tx)
+
+		merch, err := merchRepo.FindByName(ctx, item)
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				return enum.ErrItemNotFound
@@ -33,7 +42,7 @@ func (ms *merchServiceImpl) BuyMerch(userID int, item string) error {
 			return err
 		}
 
-		user, err := ms.userRepo.FindByID(userID)
+		user, err := userRepo.FindByIDForUpdate(ctx, userID)
 		if err != nil {
 			return err
 		}
@@ -42,20 +51,43 @@ func (ms *merchServiceImpl) BuyMerch(userID int, item string) error {
 			return enum.ErrBuyWithInsufficientMoney
 		}
 
-		user.Coins -= merch.Price
-		if err := ms.userRepo.Update(user); err != nil {
+		inStock, err := merchRepo.DecrementStock(ctx, item, 1)
+		if err != nil {
 			return err
 		}
+		if !inStock {
+			return enum.ErrOutOfStock
+		}
 
-		purchase := &model.Purchase{
+		purchase = &model.Purchase{
 			UserID:    userID,
 			MerchItem: item,
+			Quantity:  1,
+			UnitPrice: merch.Price,
+			Total:     merch.Price,
 			CreatedAt: time.Now(),
 		}
-		if err := ms.purchaseRepo.Create(purchase); err != nil {
+		if err := purchaseRepo.Create(ctx, purchase); err != nil {
 			return err
 		}
 
-		return nil
+		entry := model.NewJournalEntry(enum.EntryPurchase, purchaseReference(purchase.ID), model.UserAccount(userID), model.SystemAccount(model.StoreAccountCode), purchase.Total)
+		return purchaseError(postEntry(ctx, userRepo, ledgerRepo, entry, user))
 	})
+	if err != nil {
+		return nil, err
+	}
+	return purchase, nil
+}
+
+func purchaseReference(purchaseID int) string {
+	return fmt.Sprintf("purchase:%d", purchaseID)
+}
+
+// purchaseError reports a balance going negative while paying for merch as a purchase the buyer can't afford
+func purchaseError(err error) error {
+	if errors.Is(err, enum.ErrInsufficientMoney) {
+		return enum.ErrBuyWithInsufficientMoney
+	}
+	return err
 }
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"github.com/ners1us/merch_store/internal/enum"
 	"github.com/ners1us/merch_store/internal/model"
 	"github.com/ners1us/merch_store/internal/repository"
@@ -15,15 +16,23 @@ func TestMerchService_BuyMerch(t *testing.T) {
 	mockUserRepo := repository.NewMockUserRepository()
 	mockMerchRepo := repository.NewMockMerchRepository()
 	mockPurchaseRepo := repository.NewMockPurchaseRepository()
-	merchService := NewMerchService(mockUserRepo, mockMerchRepo, mockPurchaseRepo)
+	mockLedgerRepo := repository.NewMockLedgerRepository()
+	merchService := NewMerchService(mockUserRepo, mockMerchRepo, mockPurchaseRepo, mockLedgerRepo)
 
 	user := &model.User{ID: 1, Coins: 1000}
 	merch := &model.Merch{Name: "pink-hoody", Price: 500}
 
 	mockMerchRepo.On("FindByName", "pink-hoody").Return(merch, nil).Once()
-	mockUserRepo.On("FindByID", 1).Return(user, nil).Once()
-	mockUserRepo.On("Update", mock.Anything).Return(nil).Once()
-	mockPurchaseRepo.On("Create", mock.Anything).Return(nil).Once()
+	mockUserRepo.On("FindByIDForUpdate", 1).Return(user, nil).Once()
+	mockMerchRepo.On("DecrementStock", "pink-hoody", 1).Return(true, nil).Once()
+	mockPurchaseRepo.On("Create", mock.MatchedBy(func(purchase *model.Purchase) bool {
+		return purchase.UnitPrice == 500 && purchase.Quantity == 1 && purchase.Total == 500 && !purchase.PriceEstimated
+	})).Return(nil).Once()
+	mockLedgerRepo.On("Post", mock.MatchedBy(func(entry *model.JournalEntry) bool {
+		return entry.Type == enum.EntryPurchase && entry.Postings[0].AccountCode == "user:1" && entry.Postings[0].Amount == -500
+	})).Return(nil).Once()
+	mockLedgerRepo.On("GetBalance", "user:1").Return(500, nil).Once()
+	mockUserRepo.On("UpdateCoins", 1, 500).Return(nil).Once()
 	mockUserRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
 		fn := args.Get(0).(func(tx *gorm.DB) error)
 		err := fn(nil)
@@ -31,15 +40,16 @@ func TestMerchService_BuyMerch(t *testing.T) {
 	}).Return(nil).Once()
 
 	// Act
-	err := merchService.BuyMerch(1, "pink-hoody")
+	_, err := merchService.BuyMerch(context.Background(), 1, "pink-hoody")
 
 	// Assert
 	assert.NoError(t, err)
+	assert.Equal(t, 500, user.Coins)
 
 	// Arrange
 	user.Coins = 400
 	mockMerchRepo.On("FindByName", "pink-hoody").Return(merch, nil).Once()
-	mockUserRepo.On("FindByID", 1).Return(user, nil).Once()
+	mockUserRepo.On("FindByIDForUpdate", 1).Return(user, nil).Once()
 	mockUserRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
 		fn := args.Get(0).(func(tx *gorm.DB) error)
 		err := fn(nil)
@@ -47,7 +57,7 @@ func TestMerchService_BuyMerch(t *testing.T) {
 	}).Return(enum.ErrBuyWithInsufficientMoney).Once()
 
 	// Act
-	err = merchService.BuyMerch(1, "pink-hoody")
+	_, err = merchService.BuyMerch(context.Background(), 1, "pink-hoody")
 
 	// Assert
 	assert.Error(t, err)
@@ -62,9 +72,59 @@ func TestMerchService_BuyMerch(t *testing.T) {
 	}).Return(enum.ErrItemNotFound).Once()
 
 	// Act
-	err = merchService.BuyMerch(1, "candy")
+	_, err = merchService.BuyMerch(context.Background(), 1, "candy")
 
 	// Assert
 	assert.Error(t, err)
 	assert.Equal(t, enum.ErrItemNotFound, err)
+
+	// Arrange
+	user.Coins = 1000
+	mockMerchRepo.On("FindByName", "pink-hoody").Return(merch, nil).Once()
+	mockUserRepo.On("FindByIDForUpdate", 1).Return(user, nil).Once()
+	mockMerchRepo.On("DecrementStock", "pink-hoody", 1).Return(false, nil).Once()
+	mockUserRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(0).(func(tx *gorm.DB) error)
+		err := fn(nil)
+		assert.Equal(t, enum.ErrOutOfStock, err)
+	}).Return(enum.ErrOutOfStock).Once()
+
+	// Act
+	_, err = merchService.BuyMerch(context.Background(), 1, "pink-hoody")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, enum.ErrOutOfStock, err)
+}
+
+func TestMerchService_BuyMerchNegativeBalance(t *testing.T) {
+	// Arrange
+	mockUserRepo := repository.NewMockUserRepository()
+	mockMerchRepo := repository.NewMockMerchRepository()
+	mockPurchaseRepo := repository.NewMockPurchaseRepository()
+	mockLedgerRepo := repository.NewMockLedgerRepository()
+	merchService := NewMerchService(mockUserRepo, mockMerchRepo, mockPurchaseRepo, mockLedgerRepo)
+
+	user := &model.User{ID: 1, Coins: 1000}
+	merch := &model.Merch{Name: "pink-hoody", Price: 500}
+
+	mockMerchRepo.On("FindByName", "pink-hoody").Return(merch, nil).Once()
+	mockUserRepo.On("FindByIDForUpdate", 1).Return(user, nil).Once()
+	mockMerchRepo.On("DecrementStock", "pink-hoody", 1).Return(true, nil).Once()
+	mockPurchaseRepo.On("Create", mock.Anything).Return(nil).Once()
+	mockLedgerRepo.On("Post", mock.Anything).Return(nil).Once()
+	mockLedgerRepo.On("GetBalance", "user:1").Return(-500, nil).Once()
+	mockUserRepo.On("UpdateCoins", 1, -500).Return(enum.ErrInsufficientMoney).Once()
+	mockUserRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(0).(func(tx *gorm.DB) error)
+		err := fn(nil)
+		assert.Equal(t, enum.ErrBuyWithInsufficientMoney, err)
+	}).Return(enum.ErrBuyWithInsufficientMoney).Once()
+
+	// Act
+	_, err := merchService.BuyMerch(context.Background(), 1, "pink-hoody")
+
+	// Assert
+	assert.Equal(t, enum.ErrBuyWithInsufficientMoney, err)
+	mockUserRepo.AssertExpectations(t)
 }
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/metrics"
+	"github.com/ners1us/merch_store/internal/model"
+)
+
+type merchServiceMetrics struct {
+	MerchService
+	metrics *metrics.Metrics
+}
+
+// NewMerchServiceWithMetrics counts purchases, spent coins and failed purchases of the wrapped service
+func NewMerchServiceWithMetrics(next MerchService, m *metrics.Metrics) MerchService {
+	return &merchServiceMetrics{MerchService: next, metrics: m}
+}
+
+func (msm *merchServiceMetrics) BuyMerch(ctx context.Context, userID int, item string) (*model.Purchase, error) {
+	purchase, err := msm.MerchService.BuyMerch(ctx, userID, item)
+	if err != nil {
+		msm.metrics.PurchaseFailures.WithLabelValues(errorCode(err)).Inc()
+		return nil, err
+	}
+	recordPurchase(msm.metrics, purchase)
+	return purchase, nil
+}
+
+type orderServiceMetrics struct {
+	OrderService
+	metrics *metrics.Metrics
+}
+
+// NewOrderServiceWithMetrics counts the items of placed orders, spent coins and failed orders of the wrapped service
+func NewOrderServiceWithMetrics(next OrderService, m *metrics.Metrics) OrderService {
+	return &orderServiceMetrics{OrderService: next, metrics: m}
+}
+
+func (osm *orderServiceMetrics) PlaceOrder(ctx context.Context, userID int, lines []model.OrderLine) (*model.Order, error) {
+	order, err := osm.OrderService.PlaceOrder(ctx, userID, lines)
+	if err != nil {
+		osm.metrics.PurchaseFailures.WithLabelValues(errorCode(err)).Inc()
+		return nil, err
+	}
+	for i := range order.Purchases {
+		recordPurchase(osm.metrics, &order.Purchases[i])
+	}
+	return order, nil
+}
+
+type transferServiceMetrics struct {
+	TransferService
+	metrics *metrics.Metrics
+}
+
+// NewTransferServiceWithMetrics counts successful transfers of the wrapped service and the coins they moved
+func NewTransferServiceWithMetrics(next TransferService, m *metrics.Metrics) TransferService {
+	return &transferServiceMetrics{TransferService: next, metrics: m}
+}
+
+func (tsm *transferServiceMetrics) SendCoin(ctx context.Context, fromUserID int, toUsername string, amount int) error {
+	if err := tsm.TransferService.SendCoin(ctx, fromUserID, toUsername, amount); err != nil {
+		return err
+	}
+	tsm.metrics.Transfers.Inc()
+	tsm.metrics.TransferVolume.Add(float64(amount))
+	return nil
+}
+
+type authServiceMetrics struct {
+	AuthService
+	metrics *metrics.Metrics
+}
+
+// NewAuthServiceWithMetrics counts users created by the wrapped service on login and on registration
+func NewAuthServiceWithMetrics(next AuthService, m *metrics.Metrics) AuthService {
+	return &authServiceMetrics{AuthService: next, metrics: m}
+}
+
+func (asm *authServiceMetrics) Authenticate(ctx context.Context, username, password string) (*model.AuthResponse, error) {
+	response, err := asm.AuthService.Authenticate(ctx, username, password)
+	if err == nil && response.Registered {
+		asm.metrics.Registrations.WithLabelValues("authenticate").Inc()
+	}
+	return response, err
+}
+
+func (asm *authServiceMetrics) Register(ctx context.Context, username, password, inviteCode string) (*model.AuthResponse, error) {
+	response, err := asm.AuthService.Register(ctx, username, password, inviteCode)
+	if err == nil && response.Registered {
+		asm.metrics.Registrations.WithLabelValues("register").Inc()
+	}
+	return response, err
+}
+
+func recordPurchase(m *metrics.Metrics, purchase *model.Purchase) {
+	m.Purchases.WithLabelValues(purchase.MerchItem).Add(float64(purchase.Quantity))
+	m.CoinsSpent.Add(float64(purchase.Total))
+}
+
+// errorCode labels an error by its domain error type, errors without one count as internal errors
+func errorCode(err error) string {
+	var errorType enum.ErrorType
+	if errors.As(err, &errorType) {
+		return errorType.Code()
+	}
+	return enum.ErrInternalServer.Code()
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/metrics"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type stubMerchService struct {
+	purchase *model.Purchase
+	err      error
+}
+
+func (sms *stubMerchService) BuyMerch(ctx context.Context, userID int, item string) (*model.Purchase, error) {
+	return sms.purchase, sms.err
+}
+
+type stubOrderService struct {
+	order *model.Order
+	err   error
+}
+
+func (sos *stubOrderService) PlaceOrder(ctx context.Context, userID int, lines []model.OrderLine) (*model.Order, error) {
+	return sos.order, sos.err
+}
+
+type stubTransferService struct {
+	err error
+}
+
+func (sts *stubTransferService) SendCoin(ctx context.Context, fromUserID int, toUsername string, amount int) error {
+	return sts.err
+}
+
+type stubAuthService struct {
+	AuthService
+	response *model.AuthResponse
+}
+
+func (sas *stubAuthService) Authenticate(ctx context.Context, username, password string) (*model.AuthResponse, error) {
+	return sas.response, nil
+}
+
+func TestMerchServiceWithMetrics_BuyMerch(t *testing.T) {
+	// Arrange
+	m := metrics.New()
+	purchase := &model.Purchase{MerchItem: "cup", Quantity: 1, UnitPrice: 20, Total: 20}
+	merchService := NewMerchServiceWithMetrics(&stubMerchService{purchase: purchase}, m)
+
+	// Act
+	_, err := merchService.BuyMerch(context.Background(), 1, "cup")
+	_, _ = merchService.BuyMerch(context.Background(), 1, "cup")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.Purchases.WithLabelValues("cup")))
+	assert.Equal(t, float64(40), testutil.ToFloat64(m.CoinsSpent))
+	assert.Equal(t, 0, testutil.CollectAndCount(m.PurchaseFailures))
+}
+
+func TestMerchServiceWithMetrics_BuyMerchFailures(t *testing.T) {
+	// Arrange
+	m := metrics.New()
+	outOfStock := NewMerchServiceWithMetrics(&stubMerchService{err: enum.ErrOutOfStock}, m)
+	broken := NewMerchServiceWithMetrics(&stubMerchService{err: errors.New("connection refused")}, m)
+
+	// Act
+	_, outOfStockErr := outOfStock.BuyMerch(context.Background(), 1, "cup")
+	_, brokenErr := broken.BuyMerch(context.Background(), 1, "cup")
+
+	// Assert
+	assert.Equal(t, enum.ErrOutOfStock, outOfStockErr)
+	assert.Error(t, brokenErr)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.PurchaseFailures.WithLabelValues("out_of_stock")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.PurchaseFailures.WithLabelValues("internal_server_error")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.CoinsSpent))
+}
+
+func TestOrderServiceWithMetrics_PlaceOrder(t *testing.T) {
+	// Arrange
+	m := metrics.New()
+	order := &model.Order{Total: 140, Purchases: []model.Purchase{
+		{MerchItem: "cup", Quantity: 2, UnitPrice: 20, Total: 40},
+		{MerchItem: "book", Quantity: 2, UnitPrice: 50, Total: 100},
+	}}
+	orderService := NewOrderServiceWithMetrics(&stubOrderService{order: order}, m)
+	failingOrderService := NewOrderServiceWithMetrics(&stubOrderService{err: enum.ErrBuyWithInsufficientMoney}, m)
+
+	// Act
+	_, err := orderService.PlaceOrder(context.Background(), 1, nil)
+	_, failedErr := failingOrderService.PlaceOrder(context.Background(), 1, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, enum.ErrBuyWithInsufficientMoney, failedErr)
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.Purchases.WithLabelValues("cup")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.Purchases.WithLabelValues("book")))
+	assert.Equal(t, float64(140), testutil.ToFloat64(m.CoinsSpent))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.PurchaseFailures.WithLabelValues("buy_with_insufficient_money")))
+}
+
+func TestTransferServiceWithMetrics_SendCoin(t *testing.T) {
+	// Arrange
+	m := metrics.New()
+	transferService := NewTransferServiceWithMetrics(&stubTransferService{}, m)
+	failingTransferService := NewTransferServiceWithMetrics(&stubTransferService{err: enum.ErrInsufficientMoney}, m)
+
+	// Act
+	err := transferService.SendCoin(context.Background(), 1, "receiver", 150)
+	failedErr := failingTransferService.SendCoin(context.Background(), 1, "receiver", 5000)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, enum.ErrInsufficientMoney, failedErr)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.Transfers))
+	assert.Equal(t, float64(150), testutil.ToFloat64(m.TransferVolume))
+}
+
+func TestAuthServiceWithMetrics_Authenticate(t *testing.T) {
+	// Arrange
+	m := metrics.New()
+	newUser := NewAuthServiceWithMetrics(&stubAuthService{response: &model.AuthResponse{Registered: true}}, m)
+	existingUser := NewAuthServiceWithMetrics(&stubAuthService{response: &model.AuthResponse{}}, m)
+
+	// Act
+	_, newUserErr := newUser.Authenticate(context.Background(), "newcomer", "password")
+	_, existingUserErr := existingUser.Authenticate(context.Background(), "veteran", "password")
+
+	// Assert
+	assert.NoError(t, newUserErr)
+	assert.NoError(t, existingUserErr)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.Registrations.WithLabelValues("authenticate")))
+}
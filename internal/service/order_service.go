@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/repository"
+	"gorm.io/gorm"
+	"slices"
+	"strings"
+	"time"
+)
+
+type OrderService interface {
+	PlaceOrder(ctx context.Context, userID int, lines []model.OrderLine) (*model.Order, error)
+}
+
+type orderServiceImpl struct {
+	userRepo   repository.UserRepository
+	merchRepo  repository.MerchRepository
+	orderRepo  repository.OrderRepository
+	ledgerRepo repository.LedgerRepository
+}
+
+func NewOrderService(userRepo repository.UserRepository, merchRepo repository.MerchRepository, orderRepo repository.OrderRepository, ledgerRepo repository.LedgerRepository) OrderService {
+	return &orderServiceImpl{userRepo: userRepo, merchRepo: merchRepo, orderRepo: orderRepo, ledgerRepo: ledgerRepo}
+}
+
+func (os *orderServiceImpl) PlaceOrder(ctx context.Context, userID int, lines []model.OrderLine) (*model.Order, error) {
+	basket, err := mergeOrderLines(lines)
+	if err != nil {
+		return nil, err
+	}
+
+	var order *model.Order
+	err = os.userRepo.RunTransaction(ctx, func(tx *gorm.DB) error {
+		userRepo := os.userRepo.WithTx(tx)
+		merchRepo := os.merchRepo.WithTx(tx)
+		orderRepo := os.orderRepo.WithTx(tx)
+		ledgerRepo := os.ledgerRepo.WithTx(tx)
+
+		now := time.Now()
+		order = &model.Order{UserID: userID, CreatedAt: now}
+		for _, line := range basket {
+			merch, err := merchRepo.FindByName(ctx, line.Item)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return enum.ErrItemNotFound
+				}
+				return err
+			}
+			purchase := model.Purchase{
+				UserID:    userID,
+				MerchItem: line.Item,
+				Quantity:  line.Quantity,
+				UnitPrice: merch.Price,
+				Total:     merch.Price * line.Quantity,
+				CreatedAt: now,
+			}
+			order.Total += purchase.Total
+			order.Purchases = append(order.Purchases, purchase)
+		}
+
+		user, err := userRepo.FindByIDForUpdate(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if user.Coins < order.Total {
+			return enum.ErrBuyWithInsufficientMoney
+		}
+
+		// Stock rows are locked in item name order, so orders sharing items listed in another order cannot deadlock
+		lockOrder := slices.Clone(basket)
+		slices.SortFunc(lockOrder, func(a, b model.OrderLine) int { return strings.Compare(a.Item, b.Item) })
+		for _, line := range lockOrder {
+			inStock, err := merchRepo.DecrementStock(ctx, line.Item, line.Quantity)
+			if err != nil {
+				return err
+			}
+			if !inStock {
+				return enum.ErrOutOfStock
+			}
+		}
+
+		if err := orderRepo.Create(ctx, order); err != nil {
+			return err
+		}
+
+		for _, purchase := range order.Purchases {
+			entry := model.NewJournalEntry(enum.EntryPurchase, purchaseReference(purchase.ID), model.UserAccount(userID), model.SystemAccount(model.StoreAccountCode), purchase.Total)
+			if err := ledgerRepo.Post(ctx, entry); err != nil {
+				return err
+			}
+		}
+		return purchaseError(refreshBalances(ctx, userRepo, ledgerRepo, user))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func mergeOrderLines(lines []model.OrderLine) ([]model.OrderLine, error) {
+	if len(lines) == 0 {
+		return nil, enum.ErrEmptyOrder
+	}
+
+	var basket []model.OrderLine
+	positions := make(map[string]int, len(lines))
+	for _, line := range lines {
+		if line.Item == "" {
+			return nil, enum.ErrNotProvidedItem
+		}
+		if line.Quantity <= 0 {
+			return nil, enum.ErrInappropriateQuantity
+		}
+		if i, found := positions[line.Item]; found {
+			basket[i].Quantity += line.Quantity
+			continue
+		}
+		positions[line.Item] = len(basket)
+		basket = append(basket, line)
+	}
+	return basket, nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestOrderService_PlaceOrder(t *testing.T) {
+	// Arrange
+	mockUserRepo := repository.NewMockUserRepository()
+	mockMerchRepo := repository.NewMockMerchRepository()
+	mockOrderRepo := repository.NewMockOrderRepository()
+	mockLedgerRepo := repository.NewMockLedgerRepository()
+	orderService := NewOrderService(mockUserRepo, mockMerchRepo, mockOrderRepo, mockLedgerRepo)
+
+	user := &model.User{ID: 1, Coins: 1000}
+	socks := &model.Merch{Name: "socks", Price: 10}
+	cup := &model.Merch{Name: "cup", Price: 20}
+	lines := []model.OrderLine{{Item: "socks", Quantity: 2}, {Item: "cup", Quantity: 1}, {Item: "socks", Quantity: 1}}
+
+	mockMerchRepo.On("FindByName", "socks").Return(socks, nil).Once()
+	mockMerchRepo.On("FindByName", "cup").Return(cup, nil).Once()
+	mockUserRepo.On("FindByIDForUpdate", 1).Return(user, nil).Once()
+	mockMerchRepo.On("DecrementStock", "socks", 3).Return(true, nil).Once()
+	mockMerchRepo.On("DecrementStock", "cup", 1).Return(true, nil).Once()
+	mockOrderRepo.On("Create", mock.Anything).Return(nil).Once()
+	mockLedgerRepo.On("Post", mock.MatchedBy(func(entry *model.JournalEntry) bool {
+		return entry.Type == enum.EntryPurchase && entry.Postings[1].Amount == 30
+	})).Return(nil).Once()
+	mockLedgerRepo.On("Post", mock.MatchedBy(func(entry *model.JournalEntry) bool {
+		return entry.Type == enum.EntryPurchase && entry.Postings[1].Amount == 20
+	})).Return(nil).Once()
+	mockLedgerRepo.On("GetBalance", "user:1").Return(950, nil).Once()
+	mockUserRepo.On("UpdateCoins", 1, 950).Return(nil).Once()
+	mockUserRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(0).(func(tx *gorm.DB) error)
+		assert.NoError(t, fn(nil))
+	}).Return(nil).Once()
+
+	// Act
+	order, err := orderService.PlaceOrder(context.Background(), 1, lines)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 50, order.Total)
+	assert.Len(t, order.Purchases, 2)
+	assert.Equal(t, 3, order.Purchases[0].Quantity)
+	assert.Equal(t, 10, order.Purchases[0].UnitPrice)
+	assert.Equal(t, 30, order.Purchases[0].Total)
+	assert.Equal(t, 20, order.Purchases[1].Total)
+	assert.Equal(t, 950, user.Coins)
+	var decremented []string
+	for _, call := range mockMerchRepo.Calls {
+		if call.Method == "DecrementStock" {
+			decremented = append(decremented, call.Arguments.String(0))
+		}
+	}
+	assert.Equal(t, []string{"cup", "socks"}, decremented)
+
+	// Arrange
+	user.Coins = 40
+	mockMerchRepo.On("FindByName", "socks").Return(socks, nil).Once()
+	mockMerchRepo.On("FindByName", "cup").Return(cup, nil).Once()
+	mockUserRepo.On("FindByIDForUpdate", 1).Return(user, nil).Once()
+	mockUserRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(0).(func(tx *gorm.DB) error)
+		assert.Equal(t, enum.ErrBuyWithInsufficientMoney, fn(nil))
+	}).Return(enum.ErrBuyWithInsufficientMoney).Once()
+
+	// Act
+	order, err = orderService.PlaceOrder(context.Background(), 1, lines)
+
+	// Assert
+	assert.Equal(t, enum.ErrBuyWithInsufficientMoney, err)
+	assert.Nil(t, order)
+
+	// Arrange
+	user.Coins = 1000
+	mockMerchRepo.On("FindByName", "socks").Return(socks, nil).Once()
+	mockMerchRepo.On("FindByName", "cup").Return(cup, nil).Once()
+	mockUserRepo.On("FindByIDForUpdate", 1).Return(user, nil).Once()
+	mockMerchRepo.On("DecrementStock", "socks", 3).Return(true, nil).Once()
+	mockMerchRepo.On("DecrementStock", "cup", 1).Return(false, nil).Once()
+	mockUserRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(0).(func(tx *gorm.DB) error)
+		assert.Equal(t, enum.ErrOutOfStock, fn(nil))
+	}).Return(enum.ErrOutOfStock).Once()
+
+	// Act
+	order, err = orderService.PlaceOrder(context.Background(), 1, lines)
+
+	// Assert
+	assert.Equal(t, enum.ErrOutOfStock, err)
+	assert.Nil(t, order)
+
+	// Act
+	order, err = orderService.PlaceOrder(context.Background(), 1, nil)
+
+	// Assert
+	assert.Equal(t, enum.ErrEmptyOrder, err)
+	assert.Nil(t, order)
+
+	// Act
+	order, err = orderService.PlaceOrder(context.Background(), 1, []model.OrderLine{{Item: "cup", Quantity: 0}})
+
+	// Assert
+	assert.Equal(t, enum.ErrInappropriateQuantity, err)
+	assert.Nil(t, order)
+	mockOrderRepo.AssertExpectations(t)
+}
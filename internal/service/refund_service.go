@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/repository"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+type RefundService interface {
+	RefundPurchase(ctx context.Context, actorID int, actorRole enum.Role, purchaseID int, reason string) (*model.Purchase, error)
+}
+
+type refundServiceImpl struct {
+	userRepo     repository.UserRepository
+	merchRepo    repository.MerchRepository
+	purchaseRepo repository.PurchaseRepository
+	ledgerRepo   repository.LedgerRepository
+	window       time.Duration
+}
+
+// NewRefundService lets buyers refund their own purchases within window, admins may refund any purchase at any time
+func NewRefundService(userRepo repository.UserRepository, merchRepo repository.MerchRepository, purchaseRepo repository.PurchaseRepository, ledgerRepo repository.LedgerRepository, window time.Duration) RefundService {
+	return &refundServiceImpl{userRepo: userRepo, merchRepo: merchRepo, purchaseRepo: purchaseRepo, ledgerRepo: ledgerRepo, window: window}
+}
+
+func (rs *refundServiceImpl) RefundPurchase(ctx context.Context, actorID int, actorRole enum.Role, purchaseID int, reason string) (*model.Purchase, error) {
+	var purchase *model.Purchase
+	err := rs.userRepo.RunTransaction(ctx, func(tx *gorm.DB) error {
+		userRepo := rs.userRepo.WithTx(tx)
+		merchRepo := rs.merchRepo.WithTx(tx)
+		purchaseRepo := rs.purchaseRepo.WithTx(tx)
+		ledgerRepo := rs.ledgerRepo.WithTx(tx)
+
+		var err error
+		purchase, err = purchaseRepo.FindByIDForUpdate(ctx, purchaseID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return enum.ErrPurchaseNotFound
+			}
+			return err
+		}
+
+		isAdmin := actorRole == enum.RoleAdmin
+		if purchase.UserID != actorID && !isAdmin {
+			return enum.ErrPurchaseNotFound
+		}
+		if purchase.RefundedAt != nil {
+			return enum.ErrAlreadyRefunded
+		}
+		if !isAdmin && time.Since(purchase.CreatedAt) > rs.window {
+			return enum.ErrRefundWindowExpired
+		}
+
+		// An estimated price was never charged as such, so it cannot be paid back
+		if purchase.PriceEstimated {
+			return enum.ErrRefundUnavailable
+		}
+
+		user, err := userRepo.FindByIDForUpdate(ctx, purchase.UserID)
+		if err != nil {
+			return err
+		}
+
+		if err := merchRepo.IncrementStock(ctx, purchase.MerchItem, purchase.Quantity); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		purchase.RefundedAt = &now
+		purchase.RefundedBy = &actorID
+		purchase.RefundReason = strings.TrimSpace(reason)
+		if err := purchaseRepo.Update(ctx, purchase); err != nil {
+			return err
+		}
+
+		reference := purchaseReference(purchase.ID)
+		entry := model.NewJournalEntry(enum.EntryRefund, reference, model.SystemAccount(model.StoreAccountCode), model.UserAccount(user.ID), purchase.Total)
+		return postEntry(ctx, userRepo, ledgerRepo, entry, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return purchase, nil
+}
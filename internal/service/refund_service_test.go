@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestRefundService_RefundPurchase(t *testing.T) {
+	// Arrange
+	mockUserRepo := repository.NewMockUserRepository()
+	mockMerchRepo := repository.NewMockMerchRepository()
+	mockPurchaseRepo := repository.NewMockPurchaseRepository()
+	mockLedgerRepo := repository.NewMockLedgerRepository()
+	refundService := NewRefundService(mockUserRepo, mockMerchRepo, mockPurchaseRepo, mockLedgerRepo, time.Hour)
+
+	user := &model.User{ID: 1, Coins: 400}
+	purchase := &model.Purchase{ID: 5, UserID: 1, MerchItem: "hoody", Quantity: 2, UnitPrice: 300, Total: 600, CreatedAt: time.Now().Add(-time.Minute)}
+
+	mockPurchaseRepo.On("FindByIDForUpdate", 5).Return(purchase, nil).Once()
+	mockUserRepo.On("FindByIDForUpdate", 1).Return(user, nil).Once()
+	mockMerchRepo.On("IncrementStock", "hoody", 2).Return(nil).Once()
+	mockPurchaseRepo.On("Update", purchase).Return(nil).Once()
+	mockLedgerRepo.On("Post", mock.MatchedBy(func(entry *model.JournalEntry) bool {
+		return entry.Type == enum.EntryRefund && entry.Reference == "purchase:5" &&
+			entry.Postings[1].AccountCode == "user:1" && entry.Postings[1].Amount == 600
+	})).Return(nil).Once()
+	mockLedgerRepo.On("GetBalance", "user:1").Return(1000, nil).Once()
+	mockUserRepo.On("UpdateCoins", 1, 1000).Return(nil).Once()
+	mockUserRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(0).(func(tx *gorm.DB) error)
+		assert.NoError(t, fn(nil))
+	}).Return(nil).Once()
+
+	// Act
+	refunded, err := refundService.RefundPurchase(context.Background(), 1, enum.RoleUser, 5, " wrong size ")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, refunded.RefundedAt)
+	assert.Equal(t, 1, *refunded.RefundedBy)
+	assert.Equal(t, "wrong size", refunded.RefundReason)
+	assert.Equal(t, 1000, user.Coins)
+
+	// Arrange
+	mockPurchaseRepo.On("FindByIDForUpdate", 5).Return(purchase, nil).Once()
+	mockUserRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(0).(func(tx *gorm.DB) error)
+		assert.Equal(t, enum.ErrAlreadyRefunded, fn(nil))
+	}).Return(enum.ErrAlreadyRefunded).Once()
+
+	// Act
+	_, err = refundService.RefundPurchase(context.Background(), 1, enum.RoleUser, 5, "")
+
+	// Assert
+	assert.Equal(t, enum.ErrAlreadyRefunded, err)
+}
+
+func TestRefundService_RefundPurchaseRestrictions(t *testing.T) {
+	// Arrange
+	mockUserRepo := repository.NewMockUserRepository()
+	mockPurchaseRepo := repository.NewMockPurchaseRepository()
+	refundService := NewRefundService(mockUserRepo, repository.NewMockMerchRepository(), mockPurchaseRepo, repository.NewMockLedgerRepository(), time.Hour)
+
+	old := &model.Purchase{ID: 6, UserID: 1, MerchItem: "cup", Quantity: 1, CreatedAt: time.Now().Add(-2 * time.Hour)}
+	legacy := &model.Purchase{ID: 7, UserID: 1, MerchItem: "cup", Quantity: 1, UnitPrice: 20, Total: 20, PriceEstimated: true, CreatedAt: time.Now()}
+	mockPurchaseRepo.On("FindByIDForUpdate", 6).Return(old, nil)
+	mockPurchaseRepo.On("FindByIDForUpdate", 7).Return(legacy, nil)
+	mockPurchaseRepo.On("FindByIDForUpdate", 8).Return(&model.Purchase{}, gorm.ErrRecordNotFound)
+
+	for _, tc := range []struct {
+		actorID    int
+		role       enum.Role
+		purchaseID int
+		expected   error
+	}{
+		{1, enum.RoleUser, 6, enum.ErrRefundWindowExpired},
+		{2, enum.RoleUser, 7, enum.ErrPurchaseNotFound},
+		{1, enum.RoleUser, 8, enum.ErrPurchaseNotFound},
+		{1, enum.RoleUser, 7, enum.ErrRefundUnavailable},
+	} {
+		// Arrange
+		mockUserRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
+			fn := args.Get(0).(func(tx *gorm.DB) error)
+			assert.Equal(t, tc.expected, fn(nil))
+		}).Return(tc.expected).Once()
+
+		// Act
+		purchase, err := refundService.RefundPurchase(context.Background(), tc.actorID, tc.role, tc.purchaseID, "")
+
+		// Assert
+		assert.Equal(t, tc.expected, err)
+		assert.Nil(t, purchase)
+	}
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"github.com/golang-jwt/jwt"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/keys"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/repository"
+	"gorm.io/gorm"
+	"time"
+)
+
+type TokenService interface {
+	IssueTokens(ctx context.Context, user *model.User) (*model.AuthResponse, error)
+	ParseAccessToken(ctx context.Context, tokenStr string) (*model.Claims, error)
+	Refresh(ctx context.Context, refreshToken string) (*model.AuthResponse, error)
+	Revoke(ctx context.Context, claims *model.Claims, refreshToken string) error
+}
+
+type tokenServiceImpl struct {
+	userRepo         repository.UserRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	revokedTokenRepo repository.RevokedTokenRepository
+	keySet           *keys.KeySet
+	accessTTL        time.Duration
+	refreshTTL       time.Duration
+}
+
+func NewTokenService(userRepo repository.UserRepository, refreshTokenRepo repository.RefreshTokenRepository, revokedTokenRepo repository.RevokedTokenRepository, keySet *keys.KeySet, accessTTL, refreshTTL time.Duration) TokenService {
+	return &tokenServiceImpl{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		revokedTokenRepo: revokedTokenRepo,
+		keySet:           keySet,
+		accessTTL:        accessTTL,
+		refreshTTL:       refreshTTL,
+	}
+}
+
+func (ts *tokenServiceImpl) IssueTokens(ctx context.Context, user *model.User) (*model.AuthResponse, error) {
+	familyID, err := randomToken(16)
+	if err != nil {
+		return nil, enum.ErrGeneratingToken.Wrap(err)
+	}
+	return ts.issue(ctx, user, familyID)
+}
+
+func (ts *tokenServiceImpl) ParseAccessToken(ctx context.Context, tokenStr string) (*model.Claims, error) {
+	claims := &model.Claims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, ts.keySet.Keyfunc)
+	if err != nil || !token.Valid || claims.Id == "" {
+		return nil, enum.ErrInvalidToken
+	}
+
+	revoked, err := ts.revokedTokenRepo.Exists(ctx, claims.Id)
+	if err != nil {
+		return nil, enum.ErrInternalServer.Wrap(err)
+	}
+	if revoked {
+		return nil, enum.ErrTokenRevoked
+	}
+	return claims, nil
+}
+
+func (ts *tokenServiceImpl) Refresh(ctx context.Context, refreshToken string) (*model.AuthResponse, error) {
+	if refreshToken == "" {
+		return nil, enum.ErrInvalidRefreshToken
+	}
+
+	stored, err := ts.refreshTokenRepo.FindByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, enum.ErrInvalidRefreshToken
+		}
+		return nil, enum.ErrInternalServer.Wrap(err)
+	}
+
+	// A rotated token coming back means it has leaked, so the whole family is revoked
+	if stored.RevokedAt != nil {
+		if err := ts.refreshTokenRepo.RevokeFamily(ctx, stored.FamilyID); err != nil {
+			return nil, enum.ErrInternalServer.Wrap(err)
+		}
+		return nil, enum.ErrRefreshTokenReused
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, enum.ErrInvalidRefreshToken
+	}
+
+	rotated, err := ts.refreshTokenRepo.Revoke(ctx, stored.ID)
+	if err != nil {
+		return nil, enum.ErrInternalServer.Wrap(err)
+	}
+	if !rotated {
+		if err := ts.refreshTokenRepo.RevokeFamily(ctx, stored.FamilyID); err != nil {
+			return nil, enum.ErrInternalServer.Wrap(err)
+		}
+		return nil, enum.ErrRefreshTokenReused
+	}
+
+	user, err := ts.userRepo.FindByID(ctx, stored.UserID)
+	if err != nil {
+		return nil, enum.ErrInvalidRefreshToken
+	}
+	return ts.issue(ctx, user, stored.FamilyID)
+}
+
+func (ts *tokenServiceImpl) Revoke(ctx context.Context, claims *model.Claims, refreshToken string) error {
+	err := ts.revokedTokenRepo.Create(ctx, &model.RevokedToken{
+		JTI:       claims.Id,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+	})
+	if err != nil {
+		return enum.ErrInternalServer.Wrap(err)
+	}
+
+	if refreshToken != "" {
+		stored, err := ts.refreshTokenRepo.FindByHash(ctx, hashToken(refreshToken))
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return enum.ErrInternalServer.Wrap(err)
+		}
+		if err == nil && stored.UserID == claims.UserID {
+			if err := ts.refreshTokenRepo.RevokeFamily(ctx, stored.FamilyID); err != nil {
+				return enum.ErrInternalServer.Wrap(err)
+			}
+		}
+	}
+
+	_ = ts.revokedTokenRepo.DeleteExpired(ctx, time.Now())
+	return nil
+}
+
+func (ts *tokenServiceImpl) issue(ctx context.Context, user *model.User, familyID string) (*model.AuthResponse, error) {
+	jti, err := randomToken(16)
+	if err != nil {
+		return nil, enum.ErrGeneratingToken.Wrap(err)
+	}
+	now := time.Now()
+	claims := &model.Claims{
+		Username: user.Username,
+		UserID:   user.ID,
+		Role:     user.Role,
+		Locale:   user.Locale,
+		StandardClaims: jwt.StandardClaims{
+			Id:        jti,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(ts.accessTTL).Unix(),
+		},
+	}
+	accessToken, err := ts.keySet.Sign(claims)
+	if err != nil {
+		return nil, enum.ErrGeneratingToken.Wrap(err)
+	}
+
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return nil, enum.ErrGeneratingToken.Wrap(err)
+	}
+	err = ts.refreshTokenRepo.Create(ctx, &model.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: now.Add(ts.refreshTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, enum.ErrGeneratingToken.Wrap(err)
+	}
+
+	return &model.AuthResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(ts.accessTTL.Seconds()),
+	}, nil
+}
+
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/keys"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/ners1us/merch_store/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestTokenService_ParseAccessToken(t *testing.T) {
+	// Arrange
+	mockUserRepo := repository.NewMockUserRepository()
+	mockRefreshTokenRepo := repository.NewMockRefreshTokenRepository()
+	mockRevokedTokenRepo := repository.NewMockRevokedTokenRepository()
+	tokenService := NewTokenService(mockUserRepo, mockRefreshTokenRepo, mockRevokedTokenRepo, keys.NewHMACKeySet([]byte("secret")), time.Minute, time.Hour)
+
+	mockRefreshTokenRepo.On("Create", mock.Anything).Return(nil)
+	tokens, err := tokenService.IssueTokens(context.Background(), &model.User{ID: 1, Username: "alice"})
+	assert.NoError(t, err)
+	mockRevokedTokenRepo.On("Exists", mock.Anything).Return(false, nil).Once()
+
+	// Act
+	claims, err := tokenService.ParseAccessToken(context.Background(), tokens.Token)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, claims.UserID)
+	assert.Equal(t, "alice", claims.Username)
+	assert.NotEmpty(t, claims.Id)
+
+	// Arrange
+	mockRevokedTokenRepo.On("Exists", claims.Id).Return(true, nil).Once()
+
+	// Act
+	claims, err = tokenService.ParseAccessToken(context.Background(), tokens.Token)
+
+	// Assert
+	assert.Equal(t, enum.ErrTokenRevoked, err)
+	assert.Nil(t, claims)
+
+	// Act
+	claims, err = tokenService.ParseAccessToken(context.Background(), tokens.Token+"tampered")
+
+	// Assert
+	assert.Equal(t, enum.ErrInvalidToken, err)
+	assert.Nil(t, claims)
+}
+
+func TestTokenService_Refresh(t *testing.T) {
+	// Arrange
+	mockUserRepo := repository.NewMockUserRepository()
+	mockRefreshTokenRepo := repository.NewMockRefreshTokenRepository()
+	mockRevokedTokenRepo := repository.NewMockRevokedTokenRepository()
+	tokenService := NewTokenService(mockUserRepo, mockRefreshTokenRepo, mockRevokedTokenRepo, keys.NewHMACKeySet([]byte("secret")), time.Minute, time.Hour)
+
+	user := &model.User{ID: 1, Username: "alice"}
+	active := &model.RefreshToken{ID: 10, UserID: 1, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour)}
+	mockRefreshTokenRepo.On("FindByHash", hashToken("active-token")).Return(active, nil).Once()
+	mockRefreshTokenRepo.On("Revoke", 10).Return(true, nil).Once()
+	mockUserRepo.On("FindByID", 1).Return(user, nil).Once()
+	mockRefreshTokenRepo.On("Create", mock.MatchedBy(func(token *model.RefreshToken) bool {
+		return token.FamilyID == "family" && token.UserID == 1
+	})).Return(nil).Once()
+
+	// Act
+	tokens, err := tokenService.Refresh(context.Background(), "active-token")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tokens.Token)
+	assert.NotEqual(t, "active-token", tokens.RefreshToken)
+
+	// Arrange
+	revokedAt := time.Now()
+	rotated := &model.RefreshToken{ID: 10, UserID: 1, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}
+	mockRefreshTokenRepo.On("FindByHash", hashToken("active-token")).Return(rotated, nil).Once()
+	mockRefreshTokenRepo.On("RevokeFamily", "family").Return(nil).Once()
+
+	// Act
+	tokens, err = tokenService.Refresh(context.Background(), "active-token")
+
+	// Assert
+	assert.Equal(t, enum.ErrRefreshTokenReused, err)
+	assert.Nil(t, tokens)
+
+	// Arrange
+	expired := &model.RefreshToken{ID: 11, UserID: 1, FamilyID: "other", ExpiresAt: time.Now().Add(-time.Minute)}
+	mockRefreshTokenRepo.On("FindByHash", hashToken("expired-token")).Return(expired, nil).Once()
+
+	// Act
+	tokens, err = tokenService.Refresh(context.Background(), "expired-token")
+
+	// Assert
+	assert.Equal(t, enum.ErrInvalidRefreshToken, err)
+	assert.Nil(t, tokens)
+	mockRefreshTokenRepo.AssertExpectations(t)
+}
+
+func TestTokenService_Revoke(t *testing.T) {
+	// Arrange
+	mockUserRepo := repository.NewMockUserRepository()
+	mockRefreshTokenRepo := repository.NewMockRefreshTokenRepository()
+	mockRevokedTokenRepo := repository.NewMockRevokedTokenRepository()
+	tokenService := NewTokenService(mockUserRepo, mockRefreshTokenRepo, mockRevokedTokenRepo, keys.NewHMACKeySet([]byte("secret")), time.Minute, time.Hour)
+
+	claims := &model.Claims{UserID: 1}
+	claims.Id = "jti"
+	claims.ExpiresAt = time.Now().Add(time.Minute).Unix()
+	mockRevokedTokenRepo.On("Create", &model.RevokedToken{JTI: "jti", ExpiresAt: time.Unix(claims.ExpiresAt, 0)}).Return(nil).Once()
+	mockRevokedTokenRepo.On("DeleteExpired", mock.Anything).Return(nil).Once()
+	mockRefreshTokenRepo.On("FindByHash", hashToken("refresh-token")).Return(&model.RefreshToken{ID: 10, UserID: 1, FamilyID: "family"}, nil).Once()
+	mockRefreshTokenRepo.On("RevokeFamily", "family").Return(nil).Once()
+
+	// Act
+	err := tokenService.Revoke(context.Background(), claims, "refresh-token")
+
+	// Assert
+	assert.NoError(t, err)
+	mockRevokedTokenRepo.AssertExpectations(t)
+	mockRefreshTokenRepo.AssertExpectations(t)
+}
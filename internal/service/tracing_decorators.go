@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ners1us/merch_store/internal/model"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/ners1us/merch_store/internal/service"
+
+type merchServiceTracing struct {
+	MerchService
+	tracer trace.Tracer
+}
+
+// NewMerchServiceWithTracing wraps every purchase of the service in a span
+func NewMerchServiceWithTracing(next MerchService, provider trace.TracerProvider) MerchService {
+	return &merchServiceTracing{MerchService: next, tracer: provider.Tracer(tracerName)}
+}
+
+func (mst *merchServiceTracing) BuyMerch(ctx context.Context, userID int, item string) (*model.Purchase, error) {
+	ctx, span := mst.tracer.Start(ctx, "MerchService.BuyMerch", trace.WithAttributes(
+		attribute.Int("user.id", userID),
+		attribute.String("merch.item", item),
+	))
+	defer span.End()
+
+	purchase, err := mst.MerchService.BuyMerch(ctx, userID, item)
+	recordError(span, err)
+	return purchase, err
+}
+
+type transferServiceTracing struct {
+	TransferService
+	tracer trace.Tracer
+}
+
+// NewTransferServiceWithTracing wraps every transfer of the service in a span
+func NewTransferServiceWithTracing(next TransferService, provider trace.TracerProvider) TransferService {
+	return &transferServiceTracing{TransferService: next, tracer: provider.Tracer(tracerName)}
+}
+
+func (tst *transferServiceTracing) SendCoin(ctx context.Context, fromUserID int, toUsername string, amount int) error {
+	ctx, span := tst.tracer.Start(ctx, "TransferService.SendCoin", trace.WithAttributes(
+		attribute.Int("user.id", fromUserID),
+		attribute.Int("transfer.amount", amount),
+	))
+	defer span.End()
+
+	err := tst.TransferService.SendCoin(ctx, fromUserID, toUsername, amount)
+	recordError(span, err)
+	return err
+}
+
+type userServiceTracing struct {
+	UserService
+	tracer trace.Tracer
+}
+
+// NewUserServiceWithTracing wraps every request for the user's own info in a span
+func NewUserServiceWithTracing(next UserService, provider trace.TracerProvider) UserService {
+	return &userServiceTracing{UserService: next, tracer: provider.Tracer(tracerName)}
+}
+
+func (ust *userServiceTracing) GetUserInfo(ctx context.Context, userID int, historyLimit int) (*model.InfoResponse, error) {
+	ctx, span := ust.tracer.Start(ctx, "UserService.GetUserInfo", trace.WithAttributes(
+		attribute.Int("user.id", userID),
+		attribute.Int("history.limit", historyLimit),
+	))
+	defer span.End()
+
+	info, err := ust.UserService.GetUserInfo(ctx, userID, historyLimit)
+	recordError(span, err)
+	return info, err
+}
+
+type authServiceTracing struct {
+	AuthService
+	tracer trace.Tracer
+}
+
+// NewAuthServiceWithTracing wraps every login in a span, the password check shows as the time outside the query spans
+func NewAuthServiceWithTracing(next AuthService, provider trace.TracerProvider) AuthService {
+	return &authServiceTracing{AuthService: next, tracer: provider.Tracer(tracerName)}
+}
+
+func (ast *authServiceTracing) Authenticate(ctx context.Context, username, password string) (*model.AuthResponse, error) {
+	ctx, span := ast.tracer.Start(ctx, "AuthService.Authenticate")
+	defer span.End()
+
+	response, err := ast.AuthService.Authenticate(ctx, username, password)
+	if err == nil {
+		span.SetAttributes(attribute.Bool("auth.registered", response.Registered))
+	}
+	recordError(span, err)
+	return response, err
+}
+
+// recordError marks the span as failed with the error, including its domain code when there is one
+func recordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, errorCode(err))
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+)
+
+type stubUserService struct {
+	UserService
+	info *model.InfoResponse
+	err  error
+}
+
+func (sus *stubUserService) GetUserInfo(ctx context.Context, userID int, historyLimit int) (*model.InfoResponse, error) {
+	return sus.info, sus.err
+}
+
+func newTestTracerProvider() (*sdktrace.TracerProvider, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)), recorder
+}
+
+func TestMerchServiceWithTracing_BuyMerch(t *testing.T) {
+	// Arrange
+	provider, recorder := newTestTracerProvider()
+	purchase := &model.Purchase{MerchItem: "cup", Quantity: 1, Total: 20}
+	merchService := NewMerchServiceWithTracing(&stubMerchService{purchase: purchase}, provider)
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "GET /api/buy/:item")
+
+	// Act
+	result, err := merchService.BuyMerch(ctx, 1, "cup")
+	parent.End()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, purchase, result)
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	span := spans[0]
+	assert.Equal(t, "MerchService.BuyMerch", span.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	assert.Contains(t, span.Attributes(), attribute.String("merch.item", "cup"))
+	assert.Contains(t, span.Attributes(), attribute.Int("user.id", 1))
+	assert.Equal(t, codes.Unset, span.Status().Code)
+}
+
+func TestMerchServiceWithTracing_BuyMerchFailure(t *testing.T) {
+	// Arrange
+	provider, recorder := newTestTracerProvider()
+	merchService := NewMerchServiceWithTracing(&stubMerchService{err: enum.ErrOutOfStock}, provider)
+
+	// Act
+	_, err := merchService.BuyMerch(context.Background(), 1, "cup")
+
+	// Assert
+	assert.Equal(t, enum.ErrOutOfStock, err)
+	span := recorder.Ended()[0]
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Equal(t, "out_of_stock", span.Status().Description)
+	assert.Len(t, span.Events(), 1)
+	assert.Equal(t, "exception", span.Events()[0].Name)
+}
+
+func TestTransferServiceWithTracing_SendCoin(t *testing.T) {
+	// Arrange
+	provider, recorder := newTestTracerProvider()
+	transferService := NewTransferServiceWithTracing(&stubTransferService{}, provider)
+
+	// Act
+	err := transferService.SendCoin(context.Background(), 1, "receiver", 150)
+
+	// Assert
+	assert.NoError(t, err)
+	span := recorder.Ended()[0]
+	assert.Equal(t, "TransferService.SendCoin", span.Name())
+	assert.Contains(t, span.Attributes(), attribute.Int("transfer.amount", 150))
+}
+
+func TestUserServiceWithTracing_GetUserInfo(t *testing.T) {
+	// Arrange
+	provider, recorder := newTestTracerProvider()
+	userService := NewUserServiceWithTracing(&stubUserService{err: enum.ErrReceivingCoinsInfo}, provider)
+
+	// Act
+	_, err := userService.GetUserInfo(context.Background(), 3, 10)
+
+	// Assert
+	assert.Equal(t, enum.ErrReceivingCoinsInfo, err)
+	span := recorder.Ended()[0]
+	assert.Equal(t, "UserService.GetUserInfo", span.Name())
+	assert.Contains(t, span.Attributes(), attribute.Int("user.id", 3))
+	assert.Equal(t, codes.Error, span.Status().Code)
+}
+
+func TestAuthServiceWithTracing_Authenticate(t *testing.T) {
+	// Arrange
+	provider, recorder := newTestTracerProvider()
+	authService := NewAuthServiceWithTracing(&stubAuthService{response: &model.AuthResponse{Registered: true}}, provider)
+
+	// Act
+	_, err := authService.Authenticate(context.Background(), "newcomer", "password")
+
+	// Assert
+	assert.NoError(t, err)
+	span := recorder.Ended()[0]
+	assert.Equal(t, "AuthService.Authenticate", span.Name())
+	assert.Contains(t, span.Attributes(), attribute.Bool("auth.registered", true))
+	for _, attr := range span.Attributes() {
+		assert.NotEqual(t, "password", attr.Value.AsString())
+	}
+}
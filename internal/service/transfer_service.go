@@ -1,7 +1,9 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"github.com/ners1us/merch_store/internal/enum"
 	"github.com/ners1us/merch_store/internal/model"
 	"github.com/ners1us/merch_store/internal/repository"
@@ -10,34 +12,30 @@ import (
 )
 
 type TransferService interface {
-	SendCoin(fromUserID int, toUsername string, amount int) error
+	SendCoin(ctx context.Context, fromUserID int, toUsername string, amount int) error
 }
 
 type transferServiceImpl struct {
 	userRepo     repository.UserRepository
 	transferRepo repository.CoinTransferRepository
+	ledgerRepo   repository.LedgerRepository
 }
 
-func NewTransferService(userRepo repository.UserRepository, transferRepo repository.CoinTransferRepository) TransferService {
-	return &transferServiceImpl{userRepo: userRepo, transferRepo: transferRepo}
+func NewTransferService(userRepo repository.UserRepository, transferRepo repository.CoinTransferRepository, ledgerRepo repository.LedgerRepository) TransferService {
+	return &transferServiceImpl{userRepo: userRepo, transferRepo: transferRepo, ledgerRepo: ledgerRepo}
 }
 
-func (ts *transferServiceImpl) SendCoin(fromUserID int, toUsername string, amount int) error {
+func (ts *transferServiceImpl) SendCoin(ctx context.Context, fromUserID int, toUsername string, amount int) error {
 	if amount <= 0 {
 		return enum.ErrCoinsInappropriateAmount
 	}
 
-	return ts.userRepo.RunTransaction(func(tx *gorm.DB) error {
-		sender, err := ts.userRepo.FindByID(fromUserID)
-		if err != nil {
-			return err
-		}
-
-		if sender.Coins < amount {
-			return enum.ErrInsufficientMoney
-		}
+	return ts.userRepo.RunTransaction(ctx, func(tx *gorm.DB) error {
+		userRepo := ts.userRepo.WithTx(tx)
+		transferRepo := ts.transferRepo.WithTx(tx)
+		ledgerRepo := ts.ledgerRepo.WithTx(tx)
 
-		receiver, err := ts.userRepo.FindByUsername(toUsername)
+		receiver, err := userRepo.FindByUsername(ctx, toUsername)
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				return enum.ErrReceiverNotFound
@@ -45,18 +43,27 @@ func (ts *transferServiceImpl) SendCoin(fromUserID int, toUsername string, amoun
 			return err
 		}
 
-		if sender.Username == receiver.Username {
+		if receiver.ID == fromUserID {
 			return enum.ErrEqualReceivers
 		}
 
-		sender.Coins -= amount
-		receiver.Coins += amount
-
-		if err := ts.userRepo.Update(sender); err != nil {
-			return err
+		// Both rows are locked in ascending ID order, so opposite transfers between the same users cannot deadlock
+		lockOrder := []int{fromUserID, receiver.ID}
+		if lockOrder[0] > lockOrder[1] {
+			lockOrder[0], lockOrder[1] = lockOrder[1], lockOrder[0]
 		}
-		if err := ts.userRepo.Update(receiver); err != nil {
-			return err
+		locked := make(map[int]*model.User, len(lockOrder))
+		for _, id := range lockOrder {
+			user, err := userRepo.FindByIDForUpdate(ctx, id)
+			if err != nil {
+				return err
+			}
+			locked[id] = user
+		}
+		sender, receiver := locked[fromUserID], locked[receiver.ID]
+
+		if sender.Coins < amount {
+			return enum.ErrInsufficientMoney
 		}
 
 		transfer := &model.CoinTransfer{
@@ -65,10 +72,11 @@ func (ts *transferServiceImpl) SendCoin(fromUserID int, toUsername string, amoun
 			Amount:     amount,
 			CreatedAt:  time.Now(),
 		}
-		if err := ts.transferRepo.Create(transfer); err != nil {
+		if err := transferRepo.Create(ctx, transfer); err != nil {
 			return err
 		}
 
-		return nil
+		entry := model.NewJournalEntry(enum.EntryTransfer, fmt.Sprintf("transfer:%d", transfer.ID), model.UserAccount(sender.ID), model.UserAccount(receiver.ID), amount)
+		return postEntry(ctx, userRepo, ledgerRepo, entry, sender, receiver)
 	})
 }
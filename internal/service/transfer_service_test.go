@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"github.com/ners1us/merch_store/internal/enum"
 	"github.com/ners1us/merch_store/internal/model"
 	"github.com/ners1us/merch_store/internal/repository"
@@ -14,15 +15,24 @@ func TestTransferService_SendCoin(t *testing.T) {
 	// Arrange
 	mockUserRepo := repository.NewMockUserRepository()
 	mockTransferRepo := repository.NewMockCoinTransferRepository()
-	transferService := NewTransferService(mockUserRepo, mockTransferRepo)
+	mockLedgerRepo := repository.NewMockLedgerRepository()
+	transferService := NewTransferService(mockUserRepo, mockTransferRepo, mockLedgerRepo)
 
 	sender := &model.User{ID: 1, Username: "alice", Coins: 1000}
 	receiver := &model.User{ID: 2, Username: "bob", Coins: 500}
 
-	mockUserRepo.On("FindByID", 1).Return(sender, nil).Once()
 	mockUserRepo.On("FindByUsername", "bob").Return(receiver, nil).Once()
-	mockUserRepo.On("Update", mock.Anything).Return(nil).Times(2)
+	mockUserRepo.On("FindByIDForUpdate", 1).Return(sender, nil).Once()
+	mockUserRepo.On("FindByIDForUpdate", 2).Return(receiver, nil).Once()
 	mockTransferRepo.On("Create", mock.Anything).Return(nil).Once()
+	mockLedgerRepo.On("Post", mock.MatchedBy(func(entry *model.JournalEntry) bool {
+		return entry.Type == enum.EntryTransfer && entry.Balanced() &&
+			entry.Postings[0].AccountCode == "user:1" && entry.Postings[1].AccountCode == "user:2"
+	})).Return(nil).Once()
+	mockLedgerRepo.On("GetBalance", "user:1").Return(800, nil).Once()
+	mockLedgerRepo.On("GetBalance", "user:2").Return(700, nil).Once()
+	mockUserRepo.On("UpdateCoins", 1, 800).Return(nil).Once()
+	mockUserRepo.On("UpdateCoins", 2, 700).Return(nil).Once()
 	mockUserRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
 		fn := args.Get(0).(func(tx *gorm.DB) error)
 		err := fn(nil)
@@ -30,14 +40,18 @@ func TestTransferService_SendCoin(t *testing.T) {
 	}).Return(nil).Once()
 
 	// Act
-	err := transferService.SendCoin(1, "bob", 200)
+	err := transferService.SendCoin(context.Background(), 1, "bob", 200)
 
 	// Assert
 	assert.NoError(t, err)
+	assert.Equal(t, 800, sender.Coins)
+	assert.Equal(t, 700, receiver.Coins)
 
 	// Arrange
 	sender.Coins = 100
-	mockUserRepo.On("FindByID", 1).Return(sender, nil).Once()
+	mockUserRepo.On("FindByUsername", "bob").Return(receiver, nil).Once()
+	mockUserRepo.On("FindByIDForUpdate", 1).Return(sender, nil).Once()
+	mockUserRepo.On("FindByIDForUpdate", 2).Return(receiver, nil).Once()
 	mockUserRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
 		fn := args.Get(0).(func(tx *gorm.DB) error)
 		err := fn(nil)
@@ -45,14 +59,13 @@ func TestTransferService_SendCoin(t *testing.T) {
 	}).Return(enum.ErrInsufficientMoney).Once()
 
 	// Act
-	err = transferService.SendCoin(1, "bob", 200)
+	err = transferService.SendCoin(context.Background(), 1, "bob", 200)
 
 	// Assert
 	assert.Error(t, err)
 	assert.Equal(t, enum.ErrInsufficientMoney, err)
 
 	// Arrange
-	mockUserRepo.On("FindByID", 1).Return(sender, nil).Once()
 	mockUserRepo.On("FindByUsername", "alice").Return(sender, nil).Once()
 	mockUserRepo.On("RunTransaction", mock.Anything).Run(func(args mock.Arguments) {
 		fn := args.Get(0).(func(tx *gorm.DB) error)
@@ -61,14 +74,14 @@ func TestTransferService_SendCoin(t *testing.T) {
 	}).Return(enum.ErrEqualReceivers).Once()
 
 	// Act
-	err = transferService.SendCoin(1, "alice", 100)
+	err = transferService.SendCoin(context.Background(), 1, "alice", 100)
 
 	// Assert
 	assert.Error(t, err)
 	assert.Equal(t, enum.ErrEqualReceivers, err)
 
 	// Act
-	err = transferService.SendCoin(1, "bob", -10)
+	err = transferService.SendCoin(context.Background(), 1, "bob", -10)
 
 	// Assert
 	assert.Error(t, err)
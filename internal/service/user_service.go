@@ -1,51 +1,107 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"github.com/ners1us/merch_store/internal/enum"
+	"github.com/ners1us/merch_store/internal/i18n"
 	"github.com/ners1us/merch_store/internal/model"
 	"github.com/ners1us/merch_store/internal/repository"
+	"gorm.io/gorm"
 )
 
 type UserService interface {
-	GetUserInfo(userID int) (*model.InfoResponse, error)
+	GetUserInfo(ctx context.Context, userID int, historyLimit int) (*model.InfoResponse, error)
+	GetUserInfoByUsername(ctx context.Context, username string, historyLimit int) (*model.InfoResponse, error)
+	SetLocale(ctx context.Context, userID int, locale enum.Locale) error
+	GetLocale(ctx context.Context, userID int) (enum.Locale, error)
 }
 
 type userServiceImpl struct {
-	userRepo     repository.UserRepository
-	purchaseRepo repository.PurchaseRepository
-	transferRepo repository.CoinTransferRepository
+	userRepo       repository.UserRepository
+	purchaseRepo   repository.PurchaseRepository
+	transferRepo   repository.CoinTransferRepository
+	adjustmentRepo repository.CoinAdjustmentRepository
 }
 
-func NewUserService(userRepo repository.UserRepository, purchaseRepo repository.PurchaseRepository, transferRepo repository.CoinTransferRepository) UserService {
-	return &userServiceImpl{userRepo: userRepo, purchaseRepo: purchaseRepo, transferRepo: transferRepo}
+func NewUserService(userRepo repository.UserRepository, purchaseRepo repository.PurchaseRepository, transferRepo repository.CoinTransferRepository, adjustmentRepo repository.CoinAdjustmentRepository) UserService {
+	return &userServiceImpl{userRepo: userRepo, purchaseRepo: purchaseRepo, transferRepo: transferRepo, adjustmentRepo: adjustmentRepo}
 }
 
-func (us *userServiceImpl) GetUserInfo(userID int) (*model.InfoResponse, error) {
-	user, err := us.userRepo.FindByID(userID)
+// GetUserInfo limits every coin history list to its latest historyLimit entries, 0 returns them all
+func (us *userServiceImpl) GetUserInfo(ctx context.Context, userID int, historyLimit int) (*model.InfoResponse, error) {
+	if historyLimit < 0 {
+		return nil, enum.ErrInappropriateLimit
+	}
+
+	user, err := us.userRepo.FindByID(ctx, userID)
 	if err != nil {
-		return nil, enum.ErrReceivingCoinsInfo
+		return nil, enum.ErrReceivingCoinsInfo.Wrap(err)
 	}
 
-	inventory, err := us.purchaseRepo.GetUserPurchases(userID)
+	inventory, err := us.purchaseRepo.GetUserPurchases(ctx, userID)
 	if err != nil {
-		return nil, enum.ErrReceivingPurchaseHistory
+		return nil, enum.ErrReceivingPurchaseHistory.Wrap(err)
 	}
 
-	received, err := us.transferRepo.GetReceivedTransfers(userID)
+	received, err := us.transferRepo.GetReceivedTransfers(ctx, userID, historyLimit)
 	if err != nil {
-		return nil, enum.ErrReceivingTransferHistory
+		return nil, enum.ErrReceivingTransferHistory.Wrap(err)
 	}
-	sent, err := us.transferRepo.GetSentTransfers(userID)
+	sent, err := us.transferRepo.GetSentTransfers(ctx, userID, historyLimit)
+	if err != nil {
+		return nil, enum.ErrReceivingTransferHistory.Wrap(err)
+	}
+
+	adjustments, err := us.adjustmentRepo.GetUserAdjustments(ctx, userID, historyLimit)
 	if err != nil {
-		return nil, enum.ErrReceivingTransferHistory
+		return nil, enum.ErrReceivingAdjustments.Wrap(err)
 	}
 
 	return &model.InfoResponse{
 		Coins:     user.Coins,
 		Inventory: inventory,
 		CoinHistory: model.CoinHistory{
-			Received: received,
-			Sent:     sent,
+			Received:    received,
+			Sent:        sent,
+			Adjustments: adjustments,
 		},
 	}, nil
 }
+
+func (us *userServiceImpl) GetUserInfoByUsername(ctx context.Context, username string, historyLimit int) (*model.InfoResponse, error) {
+	user, err := us.userRepo.FindByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, enum.ErrUserNotFound
+		}
+		return nil, enum.ErrReceivingCoinsInfo.Wrap(err)
+	}
+	return us.GetUserInfo(ctx, user.ID, historyLimit)
+}
+
+// SetLocale saves the language of response texts for the user, an empty locale falls back to Accept-Language again
+func (us *userServiceImpl) SetLocale(ctx context.Context, userID int, locale enum.Locale) error {
+	if locale != "" && !i18n.Supported(locale) {
+		return enum.ErrUnsupportedLocale
+	}
+	if err := us.userRepo.UpdateLocale(ctx, userID, locale); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return enum.ErrUserNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// GetLocale returns the saved language of response texts, empty when the user hasn't chosen one
+func (us *userServiceImpl) GetLocale(ctx context.Context, userID int) (enum.Locale, error) {
+	user, err := us.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", enum.ErrUserNotFound
+		}
+		return "", err
+	}
+	return user.Locale, nil
+}
@@ -1,10 +1,12 @@
 package service
 
 import (
+	"context"
 	"github.com/ners1us/merch_store/internal/enum"
 	"github.com/ners1us/merch_store/internal/model"
 	"github.com/ners1us/merch_store/internal/repository"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 	"testing"
 )
 
@@ -13,20 +15,23 @@ func TestUserService_GetUserInfo(t *testing.T) {
 	mockUserRepo := repository.NewMockUserRepository()
 	mockPurchaseRepo := repository.NewMockPurchaseRepository()
 	mockTransferRepo := repository.NewMockCoinTransferRepository()
-	userService := NewUserService(mockUserRepo, mockPurchaseRepo, mockTransferRepo)
+	mockAdjustmentRepo := repository.NewMockCoinAdjustmentRepository()
+	userService := NewUserService(mockUserRepo, mockPurchaseRepo, mockTransferRepo, mockAdjustmentRepo)
 
 	user := &model.User{ID: 1, Coins: 1000}
 	inventory := []model.InventoryItem{{Type: "socks", Quantity: 2}}
 	received := []model.ReceivedCoinHistory{{FromUser: "alice", Amount: 100}}
 	sent := []model.SentCoinHistory{{ToUser: "bob", Amount: 50}}
+	adjustments := []model.AdjustmentHistory{{Type: enum.AdjustmentCredit, Amount: 200, Reason: "contest prize"}}
 
 	mockUserRepo.On("FindByID", 1).Return(user, nil)
 	mockPurchaseRepo.On("GetUserPurchases", 1).Return(inventory, nil)
-	mockTransferRepo.On("GetReceivedTransfers", 1).Return(received, nil)
-	mockTransferRepo.On("GetSentTransfers", 1).Return(sent, nil)
+	mockTransferRepo.On("GetReceivedTransfers", 1, 0).Return(received, nil)
+	mockTransferRepo.On("GetSentTransfers", 1, 0).Return(sent, nil)
+	mockAdjustmentRepo.On("GetUserAdjustments", 1, 0).Return(adjustments, nil)
 
 	// Act
-	info, err := userService.GetUserInfo(1)
+	info, err := userService.GetUserInfo(context.Background(), 1, 0)
 
 	// Assert
 	assert.NoError(t, err)
@@ -34,15 +39,115 @@ func TestUserService_GetUserInfo(t *testing.T) {
 	assert.Equal(t, inventory, info.Inventory)
 	assert.Equal(t, received, info.CoinHistory.Received)
 	assert.Equal(t, sent, info.CoinHistory.Sent)
+	assert.Equal(t, adjustments, info.CoinHistory.Adjustments)
 
 	// Arrange
 	mockUserRepo.On("FindByID", 2).Return(&model.User{}, enum.ErrReceivingCoinsInfo)
 
 	// Act
-	info, err = userService.GetUserInfo(2)
+	info, err = userService.GetUserInfo(context.Background(), 2, 0)
 
 	// Assert
 	assert.Error(t, err)
 	assert.Nil(t, info)
 	assert.Equal(t, enum.ErrReceivingCoinsInfo, err)
+
+	// Arrange
+	latestSent := []model.SentCoinHistory{{ToUser: "bob", Amount: 50}}
+	mockTransferRepo.On("GetReceivedTransfers", 1, 1).Return(received, nil)
+	mockTransferRepo.On("GetSentTransfers", 1, 1).Return(latestSent, nil)
+	mockAdjustmentRepo.On("GetUserAdjustments", 1, 1).Return(adjustments, nil)
+
+	// Act
+	info, err = userService.GetUserInfo(context.Background(), 1, 1)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, latestSent, info.CoinHistory.Sent)
+
+	// Act
+	info, err = userService.GetUserInfo(context.Background(), 1, -1)
+
+	// Assert
+	assert.Equal(t, enum.ErrInappropriateLimit, err)
+	assert.Nil(t, info)
+}
+
+func TestUserService_GetUserInfoByUsername(t *testing.T) {
+	// Arrange
+	mockUserRepo := repository.NewMockUserRepository()
+	mockPurchaseRepo := repository.NewMockPurchaseRepository()
+	mockTransferRepo := repository.NewMockCoinTransferRepository()
+	mockAdjustmentRepo := repository.NewMockCoinAdjustmentRepository()
+	userService := NewUserService(mockUserRepo, mockPurchaseRepo, mockTransferRepo, mockAdjustmentRepo)
+
+	mockUserRepo.On("FindByUsername", "alice").Return(&model.User{ID: 1, Username: "alice"}, nil)
+	mockUserRepo.On("FindByID", 1).Return(&model.User{ID: 1, Username: "alice", Coins: 700}, nil)
+	mockPurchaseRepo.On("GetUserPurchases", 1).Return([]model.InventoryItem{}, nil)
+	mockTransferRepo.On("GetReceivedTransfers", 1, 0).Return([]model.ReceivedCoinHistory{}, nil)
+	mockTransferRepo.On("GetSentTransfers", 1, 0).Return([]model.SentCoinHistory{}, nil)
+	mockAdjustmentRepo.On("GetUserAdjustments", 1, 0).Return([]model.AdjustmentHistory{}, nil)
+	mockUserRepo.On("FindByUsername", "ghost").Return(&model.User{}, gorm.ErrRecordNotFound)
+
+	// Act
+	info, err := userService.GetUserInfoByUsername(context.Background(), "alice", 0)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 700, info.Coins)
+
+	// Act
+	info, err = userService.GetUserInfoByUsername(context.Background(), "ghost", 0)
+
+	// Assert
+	assert.Equal(t, enum.ErrUserNotFound, err)
+	assert.Nil(t, info)
+}
+
+func TestUserService_SetLocale(t *testing.T) {
+	// Arrange
+	mockUserRepo := repository.NewMockUserRepository()
+	userService := NewUserService(mockUserRepo, repository.NewMockPurchaseRepository(), repository.NewMockCoinTransferRepository(), repository.NewMockCoinAdjustmentRepository())
+
+	mockUserRepo.On("UpdateLocale", 1, enum.LocaleEn).Return(nil).Once()
+
+	// Act
+	err := userService.SetLocale(context.Background(), 1, enum.LocaleEn)
+
+	// Assert
+	assert.NoError(t, err)
+	mockUserRepo.AssertExpectations(t)
+
+	// Arrange
+	mockUserRepo.On("UpdateLocale", 2, enum.LocaleEn).Return(gorm.ErrRecordNotFound).Once()
+
+	// Act
+	err = userService.SetLocale(context.Background(), 2, enum.LocaleEn)
+
+	// Assert
+	assert.Equal(t, enum.ErrUserNotFound, err)
+
+	// Act
+	err = userService.SetLocale(context.Background(), 1, "de")
+
+	// Assert
+	assert.Equal(t, enum.ErrUnsupportedLocale, err)
+}
+
+func TestUserService_GetLocale(t *testing.T) {
+	// Arrange
+	mockUserRepo := repository.NewMockUserRepository()
+	userService := NewUserService(mockUserRepo, repository.NewMockPurchaseRepository(), repository.NewMockCoinTransferRepository(), repository.NewMockCoinAdjustmentRepository())
+
+	mockUserRepo.On("FindByID", 1).Return(&model.User{ID: 1, Locale: enum.LocaleEn}, nil).Once()
+	mockUserRepo.On("FindByID", 2).Return(&model.User{}, gorm.ErrRecordNotFound).Once()
+
+	// Act
+	locale, err := userService.GetLocale(context.Background(), 1)
+	_, missingErr := userService.GetLocale(context.Background(), 2)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, enum.LocaleEn, locale)
+	assert.Equal(t, enum.ErrUserNotFound, missingErr)
 }
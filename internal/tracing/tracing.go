@@ -0,0 +1,71 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+	otelgorm "gorm.io/plugin/opentelemetry/tracing"
+)
+
+const (
+	ServiceName = "merch_store"
+
+	ExporterNone = "none"
+	ExporterOTLP = "otlp"
+)
+
+// Setup installs the W3C trace context propagator and a tracer provider for the exporter, none or otlp.
+// The OTLP exporter takes its endpoint, headers and protocol settings from the standard OTEL_EXPORTER_OTLP_* variables.
+// The returned shutdown flushes the spans that are not exported yet
+func Setup(ctx context.Context, exporter string) (trace.TracerProvider, func(context.Context) error, error) {
+	otel.SetTextMapPropagator(Propagator())
+
+	switch exporter {
+	case ExporterNone, "":
+		provider := noop.NewTracerProvider()
+		otel.SetTracerProvider(provider)
+		return provider, func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		spanExporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create the OTLP exporter: %w", err)
+		}
+		// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the default service name
+		res, err := resource.New(ctx,
+			resource.WithAttributes(semconv.ServiceName(ServiceName)),
+			resource.WithFromEnv(),
+			resource.WithTelemetrySDK(),
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to describe the service resource: %w", err)
+		}
+		provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(spanExporter), sdktrace.WithResource(res))
+		otel.SetTracerProvider(provider)
+		return provider, provider.Shutdown, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown traces exporter %q", exporter)
+	}
+}
+
+// Propagator reads and writes the W3C traceparent, tracestate and baggage headers
+func Propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
+
+// InstrumentDB starts a span for every query of the database, queries are recorded without their bound values
+func InstrumentDB(db *gorm.DB, provider trace.TracerProvider) error {
+	return db.Use(otelgorm.NewPlugin(
+		otelgorm.WithTracerProvider(provider),
+		otelgorm.WithoutQueryVariables(),
+		otelgorm.WithoutMetrics(),
+	))
+}
@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"testing"
+)
+
+func TestSetup_DefaultsToNoop(t *testing.T) {
+	// Act
+	provider, shutdown, err := Setup(context.Background(), "")
+
+	// Assert
+	assert.NoError(t, err)
+	_, span := provider.Tracer("test").Start(context.Background(), "operation")
+	assert.False(t, span.IsRecording())
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestSetup_OTLP(t *testing.T) {
+	// Arrange
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://127.0.0.1:4318")
+	t.Setenv("OTEL_SERVICE_NAME", "merch_store_test")
+
+	// Act
+	provider, shutdown, err := Setup(context.Background(), ExporterOTLP)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.IsType(t, &sdktrace.TracerProvider{}, provider)
+	_, span := provider.Tracer("test").Start(context.Background(), "operation")
+	assert.True(t, span.IsRecording())
+	assert.Equal(t, "merch_store_test", attributeValue(span.(sdktrace.ReadOnlySpan), "service.name"))
+	span.End()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = shutdown(ctx)
+}
+
+func TestSetup_RejectsUnknownExporter(t *testing.T) {
+	// Act
+	_, _, err := Setup(context.Background(), "zipkin")
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestPropagator_W3CTraceContext(t *testing.T) {
+	// Arrange
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	// Act
+	ctx := Propagator().Extract(context.Background(), propagation.HeaderCarrier(header))
+	injected := http.Header{}
+	Propagator().Inject(ctx, propagation.HeaderCarrier(injected))
+
+	// Assert
+	spanContext := trace.SpanContextFromContext(ctx)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spanContext.TraceID().String())
+	assert.True(t, spanContext.IsRemote())
+	assert.Equal(t, header.Get("traceparent"), injected.Get("traceparent"))
+}
+
+func attributeValue(span sdktrace.ReadOnlySpan, key string) string {
+	for _, attr := range span.Resource().Attributes() {
+		if string(attr.Key) == key {
+			return attr.Value.AsString()
+		}
+	}
+	return ""
+}